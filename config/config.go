@@ -1,38 +1,204 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // embedded so APP_TIMEZONE loads on hosts without zoneinfo
 )
 
+const DefaultJWTSecret = "default-secret-change-me"
+
 type Config struct {
-	Port           string
-	GinMode        string
-	DBHost         string
-	DBPort         string
-	DBUser         string
-	DBPassword     string
-	DBName         string
-	JWTSecret      string
-	JWTExpiryHours int
+	Port                     string
+	GinMode                  string
+	DBHost                   string
+	DBPort                   string
+	DBUser                   string
+	DBPassword               string
+	DBName                   string
+	JWTSecret                string
+	JWTExpiryHours           int
+	JWTAlgorithm             string
+	JWTIssuer                string
+	JWTAudience              string
+	JWTKeyRotationHours      int
+	AppBaseURL               string
+	FrontendURL              string
+	MailDriver               string
+	MailFrom                 string
+	SMSDriver                string
+	OutboxDir                string
+	StorageDriver            string
+	StorageDir               string
+	MaxUploadMB              int
+	VerificationValidityDays int
+	MediaURLSecret           string
+	MediaURLTTLMinutes       int
+	LoginMaxFailures         int
+	LoginIPMaxFailures       int
+	LoginLockoutMinutes      int
+	LoginLimiterStore        string
+	AuditRetentionDays       int
+	AccountDeletionGraceDays int
+	DefaultLocale            string
+	AppTimezone              string
+	PreferredWorkerMinutes   int
+	CheckinRadiusMeters      int
+	Location                 *time.Location // loaded from AppTimezone; worker schedules use it
+	OIDCProviders            map[string]OIDCProviderConfig
+}
+
+// OIDCProviderConfig describes an OpenID Connect provider enabled through
+// OIDC_PROVIDERS, e.g. OIDC_PROVIDERS=google with OIDC_GOOGLE_ISSUER,
+// OIDC_GOOGLE_CLIENT_ID and OIDC_GOOGLE_CLIENT_SECRET.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
+// SupportedLocales lists the languages served by the API. Catalog content
+// stored on the models themselves is in DEFAULT_LOCALE.
+var SupportedLocales = []string{"en", "vi"}
+
 var AppConfig *Config
 
 func Load() {
-	jwtExpiry, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
-
 	AppConfig = &Config{
-		Port:           getEnv("PORT", "8080"),
-		GinMode:        getEnv("GIN_MODE", "debug"),
-		DBHost:         getEnv("DB_HOST", "localhost"),
-		DBPort:         getEnv("DB_PORT", "5432"),
-		DBUser:         getEnv("DB_USER", "postgres"),
-		DBPassword:     getEnv("DB_PASSWORD", ""),
-		DBName:         getEnv("DB_NAME", "btaskee"),
-		JWTSecret:      getEnv("JWT_SECRET", "default-secret-change-me"),
-		JWTExpiryHours: jwtExpiry,
+		Port:                     getEnv("PORT", "8080"),
+		GinMode:                  getEnv("GIN_MODE", "debug"),
+		DBHost:                   getEnv("DB_HOST", "localhost"),
+		DBPort:                   getEnv("DB_PORT", "5432"),
+		DBUser:                   getEnv("DB_USER", "postgres"),
+		DBPassword:               getEnv("DB_PASSWORD", ""),
+		DBName:                   getEnv("DB_NAME", "btaskee"),
+		JWTSecret:                getEnv("JWT_SECRET", DefaultJWTSecret),
+		JWTExpiryHours:           getEnvInt("JWT_EXPIRY_HOURS", 24),
+		JWTAlgorithm:             getEnv("JWT_ALGORITHM", "RS256"),
+		JWTIssuer:                getEnv("JWT_ISSUER", "goodstuff"),
+		JWTAudience:              getEnv("JWT_AUDIENCE", "goodstuff-api"),
+		JWTKeyRotationHours:      getEnvInt("JWT_KEY_ROTATION_HOURS", 720),
+		AppBaseURL:               getEnv("APP_BASE_URL", "http://localhost:8080"),
+		FrontendURL:              getEnv("FRONTEND_URL", "http://localhost:3000"),
+		MailDriver:               getEnv("MAIL_DRIVER", "log"),
+		MailFrom:                 getEnv("MAIL_FROM", "no-reply@goodstuff.local"),
+		SMSDriver:                getEnv("SMS_DRIVER", "log"),
+		OutboxDir:                getEnv("OUTBOX_DIR", "./outbox"),
+		StorageDriver:            getEnv("STORAGE_DRIVER", "local"),
+		StorageDir:               getEnv("STORAGE_DIR", "./storage"),
+		MaxUploadMB:              getEnvInt("MAX_UPLOAD_MB", 10),
+		VerificationValidityDays: getEnvInt("VERIFICATION_VALIDITY_DAYS", 365),
+		MediaURLTTLMinutes:       getEnvInt("MEDIA_URL_TTL_MINUTES", 60),
+		LoginMaxFailures:         getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures:       getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		LoginLockoutMinutes:      getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+		LoginLimiterStore:        getEnv("LOGIN_LIMITER_STORE", "postgres"),
+		AuditRetentionDays:       getEnvInt("AUDIT_RETENTION_DAYS", 365),
+		AccountDeletionGraceDays: getEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+		DefaultLocale:            strings.ToLower(getEnv("DEFAULT_LOCALE", "en")),
+		AppTimezone:              getEnv("APP_TIMEZONE", "Asia/Ho_Chi_Minh"),
+		PreferredWorkerMinutes:   getEnvInt("PREFERRED_WORKER_MINUTES", 30),
+		CheckinRadiusMeters:      getEnvInt("CHECKIN_RADIUS_METERS", 300),
+	}
+	AppConfig.MediaURLSecret = getEnv("MEDIA_URL_SECRET", AppConfig.JWTSecret)
+	AppConfig.Location, _ = time.LoadLocation(AppConfig.AppTimezone)
+	AppConfig.OIDCProviders = loadOIDCProviders(AppConfig.AppBaseURL)
+}
+
+func loadOIDCProviders(baseURL string) map[string]OIDCProviderConfig {
+	providers := map[string]OIDCProviderConfig{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", baseURL+"/api/v1/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
 	}
+	return providers
+}
+
+// Validate rejects configurations that are unsafe to run with.
+func (c *Config) Validate() error {
+	if c.GinMode == "release" && c.JWTSecret == DefaultJWTSecret {
+		return errors.New("JWT_SECRET must be changed from the default in release mode")
+	}
+
+	switch c.JWTAlgorithm {
+	case "RS256", "EdDSA":
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q (use RS256 or EdDSA)", c.JWTAlgorithm)
+	}
+
+	for _, driver := range []string{c.MailDriver, c.SMSDriver} {
+		if driver != "log" && driver != "file" {
+			return fmt.Errorf("unsupported delivery driver %q (use log or file)", driver)
+		}
+	}
+
+	if c.StorageDriver != "local" {
+		return fmt.Errorf("unsupported STORAGE_DRIVER %q (use local)", c.StorageDriver)
+	}
+
+	if c.MaxUploadMB <= 0 || c.VerificationValidityDays <= 0 || c.MediaURLTTLMinutes <= 0 {
+		return errors.New("MAX_UPLOAD_MB, VERIFICATION_VALIDITY_DAYS and MEDIA_URL_TTL_MINUTES must be positive")
+	}
+
+	if c.GinMode == "release" && c.MediaURLSecret == DefaultJWTSecret {
+		return errors.New("MEDIA_URL_SECRET must be set in release mode")
+	}
+
+	if c.LoginLimiterStore != "memory" && c.LoginLimiterStore != "postgres" {
+		return fmt.Errorf("unsupported LOGIN_LIMITER_STORE %q (use memory or postgres)", c.LoginLimiterStore)
+	}
+
+	if c.LoginMaxFailures <= 0 || c.LoginIPMaxFailures <= 0 || c.LoginLockoutMinutes <= 0 {
+		return errors.New("LOGIN_MAX_FAILURES, LOGIN_IP_MAX_FAILURES and LOGIN_LOCKOUT_MINUTES must be positive")
+	}
+
+	// Zero keeps audit entries forever / deletes accounts on the next run
+	if c.AuditRetentionDays < 0 || c.AccountDeletionGraceDays < 0 {
+		return errors.New("AUDIT_RETENTION_DAYS and ACCOUNT_DELETION_GRACE_DAYS must not be negative")
+	}
+
+	if !IsSupportedLocale(c.DefaultLocale) {
+		return fmt.Errorf("unsupported DEFAULT_LOCALE %q (use one of %s)", c.DefaultLocale, strings.Join(SupportedLocales, ", "))
+	}
+
+	if c.PreferredWorkerMinutes <= 0 || c.CheckinRadiusMeters <= 0 {
+		return errors.New("PREFERRED_WORKER_MINUTES and CHECKIN_RADIUS_METERS must be positive")
+	}
+
+	if c.Location == nil {
+		return fmt.Errorf("unknown APP_TIMEZONE %q", c.AppTimezone)
+	}
+
+	for name, provider := range c.OIDCProviders {
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return fmt.Errorf("OIDC provider %q needs an issuer and client ID", name)
+		}
+	}
+
+	if c.JWTExpiryHours <= 0 || c.JWTKeyRotationHours <= 0 {
+		return errors.New("JWT_EXPIRY_HOURS and JWT_KEY_ROTATION_HOURS must be positive")
+	}
+
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -41,3 +207,19 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func IsSupportedLocale(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
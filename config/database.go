@@ -24,6 +24,8 @@ func InitDatabase() {
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Report unique violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 
 	if err != nil {
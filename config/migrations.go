@@ -0,0 +1,290 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a one-off schema or data change that AutoMigrate cannot
+// express. Migrations run once, in order, after AutoMigrate.
+type Migration struct {
+	ID string
+	Up func(tx *gorm.DB) error
+}
+
+type SchemaMigration struct {
+	ID        string `gorm:"type:varchar(128);primary_key"`
+	AppliedAt time.Time
+}
+
+// Arbitrary constant used with pg_advisory_lock so concurrent instances do
+// not apply the same migration twice.
+const migrationLockID = 727362
+
+var migrations = []Migration{
+	{
+		// Phone became optional for social logins, so uniqueness only
+		// applies to non-empty numbers
+		ID: "0001_users_phone_unique_when_present",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_users_phone").Error
+		},
+	},
+	{
+		// The audit log is append-only; rows can only be removed by the
+		// retention job, which sets goodstuff.audit_purge for its transaction
+		ID: "0002_audit_logs_append_only",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+				BEGIN
+					IF TG_OP = 'DELETE' AND current_setting('goodstuff.audit_purge', true) = 'on' THEN
+						RETURN OLD;
+					END IF;
+					RAISE EXCEPTION 'audit_logs is append-only';
+				END;
+				$$ LANGUAGE plpgsql;
+
+				DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+				CREATE TRIGGER audit_logs_append_only
+					BEFORE UPDATE OR DELETE ON audit_logs
+					FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+			`).Error
+		},
+	},
+	{
+		// Booking durations moved from fractional hours to whole minutes,
+		// matching the service limits
+		ID: "0003_bookings_duration_minutes",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn("bookings", "duration_hours") {
+				return nil
+			}
+			if err := tx.Exec("UPDATE bookings SET duration_minutes = ROUND(duration_hours * 60) WHERE duration_minutes = 0").Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn("bookings", "duration_hours")
+		},
+	},
+	{
+		// Categories became a tree, so names only need to be unique among
+		// siblings
+		ID: "0004_service_categories_unique_name_per_parent",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				DROP INDEX IF EXISTS idx_service_categories_name;
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_service_categories_parent_name
+					ON service_categories (COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), LOWER(name))
+					WHERE deleted_at IS NULL;
+			`).Error
+		},
+	},
+	{
+		// Catalog search: accent-insensitive full-text vectors kept up to
+		// date by triggers, plus trigram indexes for autocomplete. unaccent is
+		// not immutable, so it is wrapped for use in index expressions.
+		ID: "0005_catalog_search",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				CREATE EXTENSION IF NOT EXISTS unaccent;
+				CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+				CREATE OR REPLACE FUNCTION goodstuff_unaccent(text) RETURNS text AS $$
+					SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+				$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+				DO $$
+				BEGIN
+					IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'goodstuff_search') THEN
+						CREATE TEXT SEARCH CONFIGURATION goodstuff_search (COPY = english);
+						ALTER TEXT SEARCH CONFIGURATION goodstuff_search
+							ALTER MAPPING FOR asciiword, asciihword, hword_asciipart, word, hword, hword_part
+							WITH unaccent, english_stem;
+					END IF;
+				END;
+				$$;
+
+				ALTER TABLE services ADD COLUMN IF NOT EXISTS search_vector tsvector;
+				ALTER TABLE service_categories ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+				CREATE OR REPLACE FUNCTION catalog_search_vector() RETURNS trigger AS $$
+				BEGIN
+					NEW.search_vector :=
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.name, '')), 'A') ||
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.description, '')), 'B');
+					RETURN NEW;
+				END;
+				$$ LANGUAGE plpgsql;
+
+				DROP TRIGGER IF EXISTS services_search_vector ON services;
+				CREATE TRIGGER services_search_vector
+					BEFORE INSERT OR UPDATE OF name, description ON services
+					FOR EACH ROW EXECUTE FUNCTION catalog_search_vector();
+
+				DROP TRIGGER IF EXISTS service_categories_search_vector ON service_categories;
+				CREATE TRIGGER service_categories_search_vector
+					BEFORE INSERT OR UPDATE OF name, description ON service_categories
+					FOR EACH ROW EXECUTE FUNCTION catalog_search_vector();
+
+				UPDATE services SET name = name;
+				UPDATE service_categories SET name = name;
+
+				CREATE INDEX IF NOT EXISTS idx_services_search_vector ON services USING GIN (search_vector);
+				CREATE INDEX IF NOT EXISTS idx_service_categories_search_vector ON service_categories USING GIN (search_vector);
+				CREATE INDEX IF NOT EXISTS idx_services_name_trgm
+					ON services USING GIN (LOWER(goodstuff_unaccent(name)) gin_trgm_ops);
+				CREATE INDEX IF NOT EXISTS idx_service_categories_name_trgm
+					ON service_categories USING GIN (LOWER(goodstuff_unaccent(name)) gin_trgm_ops);
+			`).Error
+		},
+	},
+	{
+		// Search vectors also cover every translation, so a catalog item
+		// can be found in any supported language
+		ID: "0006_catalog_search_translations",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				CREATE OR REPLACE FUNCTION services_search_vector() RETURNS trigger AS $$
+				BEGIN
+					NEW.search_vector :=
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.name, '')), 'A') ||
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.description, '')), 'B') ||
+						COALESCE((
+							SELECT setweight(to_tsvector('goodstuff_search', string_agg(t.name, ' ')), 'A') ||
+								setweight(to_tsvector('goodstuff_search', string_agg(COALESCE(t.description, ''), ' ')), 'B')
+							FROM service_translations t WHERE t.service_id = NEW.id
+						), ''::tsvector);
+					RETURN NEW;
+				END;
+				$$ LANGUAGE plpgsql;
+
+				CREATE OR REPLACE FUNCTION service_categories_search_vector() RETURNS trigger AS $$
+				BEGIN
+					NEW.search_vector :=
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.name, '')), 'A') ||
+						setweight(to_tsvector('goodstuff_search', COALESCE(NEW.description, '')), 'B') ||
+						COALESCE((
+							SELECT setweight(to_tsvector('goodstuff_search', string_agg(t.name, ' ')), 'A') ||
+								setweight(to_tsvector('goodstuff_search', string_agg(COALESCE(t.description, ''), ' ')), 'B')
+							FROM category_translations t WHERE t.category_id = NEW.id
+						), ''::tsvector);
+					RETURN NEW;
+				END;
+				$$ LANGUAGE plpgsql;
+
+				DROP TRIGGER IF EXISTS services_search_vector ON services;
+				CREATE TRIGGER services_search_vector
+					BEFORE INSERT OR UPDATE OF name, description ON services
+					FOR EACH ROW EXECUTE FUNCTION services_search_vector();
+
+				DROP TRIGGER IF EXISTS service_categories_search_vector ON service_categories;
+				CREATE TRIGGER service_categories_search_vector
+					BEFORE INSERT OR UPDATE OF name, description ON service_categories
+					FOR EACH ROW EXECUTE FUNCTION service_categories_search_vector();
+
+				DROP FUNCTION IF EXISTS catalog_search_vector();
+
+				-- Touching the parent's name re-runs its search vector trigger
+				CREATE OR REPLACE FUNCTION service_translations_reindex() RETURNS trigger AS $$
+				BEGIN
+					UPDATE services SET name = name
+					WHERE id = CASE WHEN TG_OP = 'DELETE' THEN OLD.service_id ELSE NEW.service_id END;
+					RETURN NULL;
+				END;
+				$$ LANGUAGE plpgsql;
+
+				CREATE OR REPLACE FUNCTION category_translations_reindex() RETURNS trigger AS $$
+				BEGIN
+					UPDATE service_categories SET name = name
+					WHERE id = CASE WHEN TG_OP = 'DELETE' THEN OLD.category_id ELSE NEW.category_id END;
+					RETURN NULL;
+				END;
+				$$ LANGUAGE plpgsql;
+
+				DROP TRIGGER IF EXISTS service_translations_reindex ON service_translations;
+				CREATE TRIGGER service_translations_reindex
+					AFTER INSERT OR UPDATE OR DELETE ON service_translations
+					FOR EACH ROW EXECUTE FUNCTION service_translations_reindex();
+
+				DROP TRIGGER IF EXISTS category_translations_reindex ON category_translations;
+				CREATE TRIGGER category_translations_reindex
+					AFTER INSERT OR UPDATE OR DELETE ON category_translations
+					FOR EACH ROW EXECUTE FUNCTION category_translations_reindex();
+
+				UPDATE services SET name = name;
+				UPDATE service_categories SET name = name;
+			`).Error
+		},
+	},
+	{
+		// Worker search looks workers up by offered service and checks
+		// their open bookings for schedule conflicts
+		ID: "0007_worker_search_indexes",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				CREATE INDEX IF NOT EXISTS idx_worker_services_service ON worker_services (service_id, worker_id);
+				CREATE INDEX IF NOT EXISTS idx_bookings_worker_open_schedule ON bookings (worker_id, scheduled_at)
+					WHERE deleted_at IS NULL AND status IN ('pending', 'confirmed', 'in_progress');
+			`).Error
+		},
+	},
+	{
+		// Role scopes and booking regions are compared after folding case,
+		// accents and spacing, so "Hà Nội" and "hanoi" match
+		ID: "0008_normalized_scope_values",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				CREATE OR REPLACE FUNCTION goodstuff_scope_value(text) RETURNS text AS $$
+					SELECT regexp_replace(LOWER(goodstuff_unaccent($1)), '[^a-z0-9]+', '', 'g')
+				$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+				UPDATE bookings SET region = goodstuff_scope_value(region) WHERE region <> '';
+				UPDATE user_role_assignments SET scope = (
+					SELECT COALESCE(jsonb_object_agg(LOWER(TRIM(key)), goodstuff_scope_value(value)), '{}'::jsonb)
+					FROM jsonb_each_text(scope)
+				);
+			`).Error
+		},
+	},
+}
+
+func RunMigrations() {
+	if err := DB.AutoMigrate(&SchemaMigration{}); err != nil {
+		log.Fatalf("Failed to prepare migrations: %v", err)
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+
+		var applied []string
+		if err := tx.Model(&SchemaMigration{}).Pluck("id", &applied).Error; err != nil {
+			return err
+		}
+		done := make(map[string]bool, len(applied))
+		for _, id := range applied {
+			done[id] = true
+		}
+
+		for _, m := range migrations {
+			if done[m.ID] {
+				continue
+			}
+			if err := m.Up(tx); err != nil {
+				log.Printf("Migration %s failed", m.ID)
+				return err
+			}
+			if err := tx.Create(&SchemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+			log.Printf("Applied migration %s", m.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+}
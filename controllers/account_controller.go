@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeleteAccountInput struct {
+	Password string `json:"password"` // required unless the account only uses social login
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledFor time.Time `json:"deletion_scheduled_for"`
+}
+
+// ExportMyData streams a zip archive with everything stored about the user.
+func ExportMyData(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	files := map[string]interface{}{"profile.json": user}
+
+	var bookings []models.Booking
+	if err := config.DB.Preload("Service").Where("customer_id = ?", userID).Order("created_at ASC").Find(&bookings).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["bookings.json"] = bookings
+
+	var reviews []models.Review
+	if err := config.DB.Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+		Where("bookings.customer_id = ?", userID).
+		Order("reviews.created_at ASC").
+		Find(&reviews).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["reviews.json"] = reviews
+
+	var reports []models.ReviewReport
+	if err := config.DB.Where("reporter_id = ?", userID).Order("created_at ASC").Find(&reports).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["review_reports.json"] = reports
+
+	var addresses []models.Address
+	if err := config.DB.Where("user_id = ?", userID).Find(&addresses).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["addresses.json"] = addresses
+
+	var identities []models.UserIdentity
+	if err := config.DB.Where("user_id = ?", userID).Find(&identities).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["linked_accounts.json"] = identities
+
+	// What workers said about the user as a customer
+	var customerReviews []models.CustomerReview
+	if err := config.DB.Where("customer_id = ?", userID).Order("created_at ASC").Find(&customerReviews).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+		return
+	}
+	files["customer_reviews.json"] = map[string]interface{}{
+		"reliability": customerReliability(user),
+		"reviews":     customerReviews,
+	}
+
+	var worker models.Worker
+	if err := config.DB.First(&worker, "user_id = ?", userID).Error; err == nil {
+		files["worker_profile.json"] = workerProfile(worker)
+
+		var jobs []models.Booking
+		if err := config.DB.Preload("Service").Where("worker_id = ?", worker.ID).Order("created_at ASC").Find(&jobs).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+			return
+		}
+		files["worker_jobs.json"] = jobs
+
+		// Reviews of the worker's jobs that they replied to
+		var replies []models.Review
+		if err := config.DB.Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+			Where("bookings.worker_id = ? AND reviews.replied_at IS NOT NULL", worker.ID).
+			Order("reviews.replied_at ASC").
+			Find(&replies).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+			return
+		}
+		files["worker_review_replies.json"] = replies
+
+		var verifications []models.WorkerVerification
+		if err := config.DB.Preload("Documents").
+			Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+			Where("worker_id = ?", worker.ID).Order("created_at ASC").Find(&verifications).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+			return
+		}
+		files["worker_verifications.json"] = verifications
+
+		// Includes drafts that were never submitted
+		var documents []models.WorkerDocument
+		if err := config.DB.Where("worker_id = ?", worker.ID).Order("created_at ASC").Find(&documents).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export data")
+			return
+		}
+		files["worker_documents.json"] = documents
+	}
+
+	utils.RecordAudit(c, "user.data_exported", "user", userID.String(), nil)
+
+	filename := fmt.Sprintf("goodstuff-export-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			break
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(content); err != nil {
+			break
+		}
+	}
+	archive.Close()
+}
+
+// RequestAccountDeletion schedules the account for anonymization once the
+// grace period ends. All sessions are revoked; logging in again and calling
+// the restore endpoint undoes the request.
+func RequestAccountDeletion(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	// The body may be empty for accounts that only use social login
+	var input DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.DeletionScheduledFor != nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Account deletion is already scheduled")
+		return
+	}
+
+	if user.PasswordHashed != "" && !utils.CheckPassword(input.Password, user.PasswordHashed) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Password is incorrect")
+		return
+	}
+
+	if user.Role == models.RoleAdmin || user.Role == models.RoleStaff {
+		utils.ErrorResponse(c, http.StatusForbidden, "Staff accounts must be removed by an administrator")
+		return
+	}
+
+	if hasOpenBookings(userID) {
+		utils.ErrorResponse(c, http.StatusConflict, "Finish or cancel your active bookings before deleting your account")
+		return
+	}
+
+	scheduledFor := time.Now().AddDate(0, 0, config.AppConfig.AccountDeletionGraceDays)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"deletion_scheduled_for": scheduledFor,
+			"session_version":        gorm.Expr("session_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Worker{}).Where("user_id = ?", userID).Update("is_available", false).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to schedule account deletion")
+		return
+	}
+
+	utils.RecordAudit(c, "user.deletion_requested", "user", userID.String(), map[string]interface{}{
+		"scheduled_for": scheduledFor,
+	})
+	utils.SuccessResponse(c, http.StatusOK, "Account scheduled for deletion", AccountDeletionResponse{
+		DeletionScheduledFor: scheduledFor,
+	})
+}
+
+func RestoreAccount(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.DeletionScheduledFor == nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Account is not scheduled for deletion")
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("deletion_scheduled_for", nil).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to restore account")
+		return
+	}
+
+	utils.RecordAudit(c, "user.deletion_cancelled", "user", userID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Account deletion cancelled", user)
+}
+
+// hasOpenBookings reports whether the user has bookings still to be done,
+// either as the customer or as the assigned worker.
+func hasOpenBookings(userID uuid.UUID) bool {
+	var count int64
+	config.DB.Model(&models.Booking{}).
+		Where("status IN ?", models.OpenBookingStatuses).
+		Where("customer_id = ? OR worker_id IN (?)", userID, config.DB.Model(&models.Worker{}).Select("id").Where("user_id = ?", userID)).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxSavedAddresses = 20
+
+var errAddressBookFull = errors.New("address book is full")
+
+type AddressInput struct {
+	Label       string   `json:"label" binding:"required,max=64"`
+	Street      string   `json:"street" binding:"required"`
+	Ward        string   `json:"ward"`
+	District    string   `json:"district"`
+	City        string   `json:"city" binding:"required"`
+	Country     string   `json:"country" binding:"required,max=64"`
+	PostalCode  string   `json:"postal_code" binding:"max=16"`
+	Latitude    *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	AccessNotes string   `json:"access_notes" binding:"max=1000"`
+	IsDefault   bool     `json:"is_default"`
+}
+
+func GetAddresses(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var addresses []models.Address
+	if err := config.DB.Where("user_id = ?", userID).Order("is_default DESC, created_at ASC").Find(&addresses).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch addresses")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Addresses retrieved", addresses)
+}
+
+func CreateAddress(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	address := models.Address{UserID: userID}
+	applyAddressInput(&address, input)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the user so parallel requests cannot overfill the address book
+		if err := tx.Exec("SELECT id FROM users WHERE id = ? FOR UPDATE", userID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxSavedAddresses {
+			return errAddressBookFull
+		}
+
+		// The first address becomes the default
+		address.IsDefault = input.IsDefault || count == 0
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&address).Error
+	})
+	if errors.Is(err, errAddressBookFull) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Address book is full")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save address")
+		return
+	}
+
+	utils.AuditChange(c, "address.created", "address", address.ID.String(), nil, address)
+	utils.SuccessResponse(c, http.StatusCreated, "Address saved", address)
+}
+
+func UpdateAddress(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	address, ok := findOwnAddress(c, userID)
+	if !ok {
+		return
+	}
+
+	var input AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before := address
+	applyAddressInput(&address, input)
+	// Unsetting the default is done by making another address the default
+	address.IsDefault = before.IsDefault || input.IsDefault
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault && !before.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update address")
+		return
+	}
+
+	utils.AuditChange(c, "address.updated", "address", address.ID.String(), before, address)
+	utils.SuccessResponse(c, http.StatusOK, "Address updated", address)
+}
+
+func SetDefaultAddress(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	address, ok := findOwnAddress(c, userID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddress(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&address).Update("is_default", true).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update address")
+		return
+	}
+
+	utils.RecordAudit(c, "address.default_changed", "address", address.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Default address updated", address)
+}
+
+func DeleteAddress(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	address, ok := findOwnAddress(c, userID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		// Promote the oldest remaining address
+		var next models.Address
+		if err := tx.Where("user_id = ?", userID).Order("created_at ASC").First(&next).Error; err != nil {
+			return nil
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete address")
+		return
+	}
+
+	utils.AuditChange(c, "address.deleted", "address", address.ID.String(), address, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Address deleted", nil)
+}
+
+func findOwnAddress(c *gin.Context, userID uuid.UUID) (models.Address, bool) {
+	var address models.Address
+
+	addressID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid address ID")
+		return address, false
+	}
+
+	if err := config.DB.First(&address, "id = ? AND user_id = ?", addressID, userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Address not found")
+		return address, false
+	}
+	return address, true
+}
+
+func applyAddressInput(address *models.Address, input AddressInput) {
+	address.Label = strings.TrimSpace(input.Label)
+	address.Street = strings.TrimSpace(input.Street)
+	address.Ward = strings.TrimSpace(input.Ward)
+	address.District = strings.TrimSpace(input.District)
+	address.City = strings.TrimSpace(input.City)
+	address.Country = strings.TrimSpace(input.Country)
+	address.PostalCode = strings.TrimSpace(input.PostalCode)
+	address.Latitude = input.Latitude
+	address.Longitude = input.Longitude
+	address.AccessNotes = strings.TrimSpace(input.AccessNotes)
+}
+
+func clearDefaultAddress(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.Address{}).
+		Where("user_id = ? AND is_default", userID).
+		Update("is_default", false).Error
+}
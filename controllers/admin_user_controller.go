@@ -0,0 +1,437 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AdminReasonInput struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+type ChangeUserRoleInput struct {
+	Role   models.UserRole `json:"role" binding:"required,oneof=customer worker staff admin"`
+	Reason string          `json:"reason" binding:"required,min=3,max=500"`
+}
+
+type AdminUserStats struct {
+	Bookings                int64 `json:"bookings"`
+	Jobs                    int64 `json:"jobs"`
+	ReviewsWritten          int64 `json:"reviews_written"`
+	ReviewsReceived         int64 `json:"reviews_received"`
+	CustomerReviewsWritten  int64 `json:"customer_reviews_written"` // ratings of customers, as a worker
+	CustomerReviewsReceived int64 `json:"customer_reviews_received"`
+}
+
+// AdminUserFlag is an admin's note that a user needs attention.
+type AdminUserFlag struct {
+	FlaggedAt *time.Time `json:"flagged_at"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+type AdminUserDetail struct {
+	User        models.User                 `json:"user"`
+	DeletedAt   *gorm.DeletedAt             `json:"deleted_at,omitempty"`
+	Worker      *models.Worker              `json:"worker,omitempty"`
+	Roles       []models.UserRoleAssignment `json:"roles"`
+	Identities  []models.UserIdentity       `json:"identities"`
+	Flag        *AdminUserFlag              `json:"flag,omitempty"`
+	Reliability CustomerReliability         `json:"reliability"`
+	Stats       AdminUserStats              `json:"stats"`
+}
+
+// AdminUserSummary is a user in the admin list.
+type AdminUserSummary struct {
+	models.User
+	Reliability CustomerReliability `json:"reliability"`
+}
+
+var adminUserSorts = map[string]utils.SortField{
+	"created_at":        {Column: "created_at", Type: utils.SortTime},
+	"name":              {Column: "name", Type: utils.SortString},
+	"email":             {Column: "email", Type: utils.SortString},
+	"reliability_score": {Column: "reliability_score", Type: utils.SortNumber},
+}
+
+// AdminListUsers searches users by name, email or phone (q) and filters by
+// role and status (active, inactive, pending_deletion or deleted). Flagged
+// users (flagged=true) and rated customers with a low reliability score
+// (max_reliability=2.5) can be listed for review.
+func AdminListUsers(c *gin.Context) {
+	query := config.DB.Model(&models.User{})
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	switch c.Query("status") {
+	case "":
+	case "active":
+		query = query.Where("is_active = ?", true)
+	case "inactive":
+		query = query.Where("is_active = ?", false)
+	case "pending_deletion":
+		query = query.Where("deletion_scheduled_for IS NOT NULL")
+	case "deleted":
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+
+	switch c.Query("flagged") {
+	case "":
+	case "true":
+		query = query.Where("flagged_at IS NOT NULL")
+	case "false":
+		query = query.Where("flagged_at IS NULL")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "flagged must be true or false")
+		return
+	}
+
+	if raw := c.Query("max_reliability"); raw != "" {
+		maxScore, err := strconv.ParseFloat(raw, 64)
+		if err != nil || maxScore < 1 || maxScore > 5 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "max_reliability must be between 1 and 5")
+			return
+		}
+		query = query.Where("reliability_reviews > 0 AND reliability_score <= ?", maxScore)
+	}
+
+	page, err := utils.ParsePageQuery(c, adminUserSorts, "-created_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	var users []models.User
+	if err := page.Apply(query).Find(&users).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch users")
+		return
+	}
+
+	users, meta := utils.Page(page, users, func(u models.User) (interface{}, uuid.UUID) {
+		switch page.SortKey {
+		case "name":
+			return u.Name, u.ID
+		case "email":
+			return u.Email, u.ID
+		case "reliability_score":
+			return u.ReliabilityScore, u.ID
+		}
+		return u.CreatedAt, u.ID
+	})
+	summaries := make([]AdminUserSummary, len(users))
+	for i, user := range users {
+		summaries[i] = AdminUserSummary{User: user, Reliability: customerReliability(user)}
+	}
+	utils.PagedResponse(c, http.StatusOK, "Users retrieved", summaries, meta)
+}
+
+func AdminGetUser(c *gin.Context) {
+	user, ok := findUserForAdmin(c, true)
+	if !ok {
+		return
+	}
+
+	detail := AdminUserDetail{
+		User:        user,
+		Roles:       []models.UserRoleAssignment{},
+		Identities:  []models.UserIdentity{},
+		Reliability: customerReliability(user),
+	}
+	if user.DeletedAt.Valid {
+		detail.DeletedAt = &user.DeletedAt
+	}
+	if user.FlaggedAt != nil {
+		detail.Flag = &AdminUserFlag{FlaggedAt: user.FlaggedAt, Reason: user.FlagReason}
+	}
+
+	if err := loadAdminUserDetail(&detail); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User retrieved", detail)
+}
+
+// loadAdminUserDetail fills in the roles, identities, worker profile and
+// activity counts of detail.User.
+func loadAdminUserDetail(detail *AdminUserDetail) error {
+	userID := detail.User.ID
+	if err := config.DB.Preload("Role").Where("user_id = ?", userID).Find(&detail.Roles).Error; err != nil {
+		return err
+	}
+	if err := config.DB.Where("user_id = ?", userID).Find(&detail.Identities).Error; err != nil {
+		return err
+	}
+
+	// count keeps the first error and skips the remaining queries
+	var err error
+	count := func(query *gorm.DB, n *int64) {
+		if err == nil {
+			err = query.Count(n).Error
+		}
+	}
+	count(config.DB.Model(&models.Booking{}).Where("customer_id = ?", userID), &detail.Stats.Bookings)
+	count(config.DB.Model(&models.Review{}).
+		Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+		Where("bookings.customer_id = ?", userID), &detail.Stats.ReviewsWritten)
+	count(config.DB.Model(&models.CustomerReview{}).Where("customer_id = ?", userID), &detail.Stats.CustomerReviewsReceived)
+	if err != nil {
+		return err
+	}
+
+	var worker models.Worker
+	if err := config.DB.Unscoped().First(&worker, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	detail.Worker = &worker
+	count(config.DB.Model(&models.Booking{}).Where("worker_id = ?", worker.ID), &detail.Stats.Jobs)
+	count(config.DB.Model(&models.Review{}).
+		Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+		Where("bookings.worker_id = ?", worker.ID), &detail.Stats.ReviewsReceived)
+	count(config.DB.Model(&models.CustomerReview{}).Where("worker_id = ?", worker.ID), &detail.Stats.CustomerReviewsWritten)
+	return err
+}
+
+func AdminDeactivateUser(c *gin.Context) {
+	setUserActive(c, false)
+}
+
+func AdminReactivateUser(c *gin.Context) {
+	setUserActive(c, true)
+}
+
+func setUserActive(c *gin.Context, active bool) {
+	var input AdminReasonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, ok := findUserForAdmin(c, false)
+	if !ok || !canManageUser(c, user) {
+		return
+	}
+
+	if user.IsActive == active {
+		utils.ErrorResponse(c, http.StatusBadRequest, "User is already in that state")
+		return
+	}
+
+	before := user
+	updates := map[string]interface{}{"is_active": active}
+	if !active {
+		// Sign the user out everywhere
+		updates["session_version"] = gorm.Expr("session_version + 1")
+	}
+	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	action, message := "user.deactivated", "User deactivated"
+	if active {
+		action, message = "user.reactivated", "User reactivated"
+	}
+
+	config.DB.First(&user, "id = ?", user.ID)
+	utils.AuditChangeWithMetadata(c, action, "user", user.ID.String(), before, user, map[string]interface{}{"reason": input.Reason})
+	utils.SuccessResponse(c, http.StatusOK, message, user)
+}
+
+// AdminForcePasswordReset signs the user out, blocks password logins until
+// the password is reset and emails a reset link.
+func AdminForcePasswordReset(c *gin.Context) {
+	var input AdminReasonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, ok := findUserForAdmin(c, false)
+	if !ok || !canManageUser(c, user) {
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password_reset_required": true,
+		"session_version":         gorm.Expr("session_version + 1"),
+	}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	emailSent := true
+	if err := sendPasswordReset(user); err != nil {
+		log.Printf("Failed to send forced password reset to user %s: %v", user.ID, err)
+		emailSent = false
+	}
+
+	utils.RecordAudit(c, "user.password_reset_forced", "user", user.ID.String(), map[string]interface{}{
+		"reason":     input.Reason,
+		"email_sent": emailSent,
+	})
+
+	message := "Password reset required, a reset link has been emailed"
+	if !emailSent {
+		message = "Password reset required, but the reset email could not be sent; the user can request one themselves"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, nil)
+}
+
+func AdminChangeUserRole(c *gin.Context) {
+	var input ChangeUserRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, ok := findUserForAdmin(c, false)
+	if !ok || !canManageUser(c, user) {
+		return
+	}
+
+	// Granting staff or admin access is reserved to admins
+	if (input.Role == models.RoleAdmin || input.Role == models.RoleStaff) && c.GetString("userRole") != string(models.RoleAdmin) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Only admins can grant staff or admin access")
+		return
+	}
+
+	if user.Role == input.Role {
+		utils.ErrorResponse(c, http.StatusBadRequest, "User already has that role")
+		return
+	}
+
+	before := user
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Tokens carry the role, so existing sessions have to go
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"role":            input.Role,
+			"session_version": gorm.Expr("session_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+
+		// Role assignments only apply to staff; drop them so they do not
+		// come back if the user is made staff again
+		if input.Role != models.RoleStaff {
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserRoleAssignment{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if input.Role == models.RoleWorker {
+			var worker models.Worker
+			err := tx.Unscoped().First(&worker, "user_id = ?", user.ID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return tx.Create(&models.Worker{UserID: user.ID}).Error
+			}
+			if err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&worker).Update("deleted_at", nil).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to change role")
+		return
+	}
+
+	config.DB.First(&user, "id = ?", user.ID)
+	utils.AuditChangeWithMetadata(c, "user.role_changed", "user", user.ID.String(), before, user, map[string]interface{}{"reason": input.Reason})
+	utils.SuccessResponse(c, http.StatusOK, "Role changed", user)
+}
+
+// AdminRestoreUser undoes a soft delete or cancels a pending deletion
+// request. Accounts that have already been anonymized cannot be restored.
+func AdminRestoreUser(c *gin.Context) {
+	var input AdminReasonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, ok := findUserForAdmin(c, true)
+	if !ok || !canManageUser(c, user) {
+		return
+	}
+
+	if user.AnonymizedAt != nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Account has already been anonymized and cannot be restored")
+		return
+	}
+	if !user.DeletedAt.Valid && user.DeletionScheduledFor == nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "User is not deleted")
+		return
+	}
+
+	before := user
+	if err := config.DB.Unscoped().Model(&user).Updates(map[string]interface{}{
+		"deleted_at":             nil,
+		"deletion_scheduled_for": nil,
+	}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to restore user")
+		return
+	}
+
+	config.DB.First(&user, "id = ?", user.ID)
+	utils.AuditChangeWithMetadata(c, "user.restored", "user", user.ID.String(), before, user, map[string]interface{}{"reason": input.Reason})
+	utils.SuccessResponse(c, http.StatusOK, "User restored", user)
+}
+
+func findUserForAdmin(c *gin.Context, includeDeleted bool) (models.User, bool) {
+	var user models.User
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return user, false
+	}
+
+	query := config.DB
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	if err := query.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return user, false
+	}
+	return user, true
+}
+
+// canManageUser stops admins from locking themselves out and keeps staff
+// from acting on admin accounts.
+func canManageUser(c *gin.Context, user models.User) bool {
+	if user.ID == c.MustGet("userID").(uuid.UUID) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot perform this action on your own account")
+		return false
+	}
+	if user.Role == models.RoleAdmin && c.GetString("userRole") != string(models.RoleAdmin) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Only admins can manage admin accounts")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var auditSorts = map[string]utils.SortField{
+	"created_at": {Column: "created_at", Type: utils.SortTime},
+}
+
+// GetAuditLog lists audit entries, newest first. Filters: actor_id,
+// impersonator_id, action (a trailing ".*" matches a prefix such as
+// "booking.*"), resource_type, resource_id, request_id, from and to (RFC 3339).
+func GetAuditLog(c *gin.Context) {
+	query := config.DB.Model(&models.AuditLog{})
+
+	for _, param := range []string{"actor_id", "impersonator_id"} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				utils.ParamErrorResponse(c, &utils.ParamError{Message: "Invalid filter value", Param: param})
+				return
+			}
+			query = query.Where(param+" = ?", id)
+		}
+	}
+
+	if action := c.Query("action"); action != "" {
+		if prefix, ok := strings.CutSuffix(action, ".*"); ok {
+			query = query.Where("action LIKE ?", escapeLike(prefix)+".%")
+		} else {
+			query = query.Where("action = ?", action)
+		}
+	}
+
+	for _, param := range []string{"resource_type", "resource_id", "request_id"} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(param+" = ?", value)
+		}
+	}
+
+	from, to, err := utils.ParseTimeRange(c, "from", "to")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	page, err := utils.ParsePageQuery(c, auditSorts, "-created_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := page.Apply(query).Find(&entries).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch audit log")
+		return
+	}
+
+	entries, meta := utils.Page(page, entries, func(e models.AuditLog) (interface{}, uuid.UUID) {
+		return e.CreatedAt, e.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Audit log retrieved", entries, meta)
+}
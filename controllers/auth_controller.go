@@ -1,14 +1,26 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/DucLUT/goodstuff/config"
 	"github.com/DucLUT/goodstuff/models"
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+const passwordResetTTL = time.Hour
+
+var errResetTokenUsed = errors.New("reset token already used")
+
 type RegisterInput struct {
 	Email    string          `json:"email" binding:"required,email"`
 	Phone    string          `json:"phone" binding:"required"`
@@ -22,6 +34,15 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
 type AuthResponse struct {
 	Token string      `json:"token"`
 	User  models.User `json:"user"`
@@ -74,8 +95,18 @@ func Register(c *gin.Context) {
 		config.DB.Create(&worker)
 	}
 
+	utils.AuditChange(c, "user.registered", "user", user.ID.String(), nil, user)
+
+	// Send verification messages; the account stays restricted until both are confirmed
+	if err := sendEmailVerification(user); err != nil {
+		log.Printf("Failed to send email verification to user %s: %v", user.ID, err)
+	}
+	if err := sendPhoneVerification(user); err != nil {
+		log.Printf("Failed to send phone verification to user %s: %v", user.ID, err)
+	}
+
 	// Generate token
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
 		return
@@ -94,23 +125,93 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// The throttle and the lookup must agree on which account is meant
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+
+	now := time.Now()
+	ipKey := "ip:" + c.ClientIP()
+	accountKey := "account:" + email
+
+	// Refuse early while either the client or the account is backing off
+	throttles := []loginThrottleKey{
+		{throttle: utils.IPLoginThrottle, key: ipKey, scope: "ip"},
+		{throttle: utils.AccountLoginThrottle, key: accountKey, scope: "account"},
+	}
+	for _, check := range throttles {
+		wait, err := check.throttle.Check(check.key, now)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to process login")
+			return
+		}
+		if wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many login attempts, please try again later")
+			return
+		}
+	}
+
+	// Count the attempt before checking the password so parallel requests
+	// cannot exceed the lockout threshold
+	for i := range throttles {
+		attempt, allowed, err := throttles[i].throttle.Reserve(throttles[i].key, now)
+		if err != nil || !allowed {
+			releaseLoginThrottles(throttles[:i])
+		}
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to process login")
+			return
+		}
+		if !allowed {
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many login attempts, please try again later")
+			return
+		}
+		throttles[i].attempt = attempt
+	}
+
 	var user models.User
-	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+	found := config.DB.Where("LOWER(email) = ?", email).First(&user).Error == nil
+	if found {
+		found = utils.CheckPassword(input.Password, user.PasswordHashed)
+	} else {
+		utils.DummyPasswordCheck(input.Password)
+	}
+
+	// Deactivated accounts get the same answer as a wrong password so the
+	// response does not reveal account state
+	if !found || !user.IsActive {
+		recordLoginFailure(c, throttles, now)
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
-	if !utils.CheckPassword(input.Password, user.PasswordHashed) {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
+	if err := utils.IPLoginThrottle.Release(ipKey); err != nil {
+		log.Printf("Failed to release login throttle for %s: %v", ipKey, err)
+	}
+	if err := utils.AccountLoginThrottle.Succeed(accountKey); err != nil {
+		log.Printf("Failed to reset login throttle for %s: %v", accountKey, err)
+	}
+
+	if user.PasswordResetRequired {
+		utils.ErrorResponse(c, http.StatusForbidden, "A password reset is required, please use the link sent to your email")
 		return
 	}
 
-	if !user.IsActive {
-		utils.ErrorResponse(c, http.StatusForbidden, "Account is deactivated")
+	// With 2FA enabled the password only earns a short-lived challenge
+	if user.TwoFactorEnabled {
+		challenge, err := utils.GenerateTwoFactorChallenge(user)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
 		return
@@ -121,3 +222,142 @@ func Login(c *gin.Context) {
 		User:  user,
 	})
 }
+
+// loginThrottleKey is an attempt reserved against one throttle.
+type loginThrottleKey struct {
+	throttle *utils.LoginThrottle
+	key      string
+	scope    string
+	attempt  int
+}
+
+// releaseLoginThrottles gives back attempts reserved before a later
+// throttle refused the login, so they do not count as failures.
+func releaseLoginThrottles(throttles []loginThrottleKey) {
+	for _, reserved := range throttles {
+		if err := reserved.throttle.Release(reserved.key); err != nil {
+			log.Printf("Failed to release login throttle for %s: %v", reserved.key, err)
+		}
+	}
+}
+
+func recordLoginFailure(c *gin.Context, throttles []loginThrottleKey, now time.Time) {
+	for _, failure := range throttles {
+		locked, err := failure.throttle.Fail(failure.key, failure.attempt, now)
+		if err != nil {
+			log.Printf("Failed to record login failure for %s: %v", failure.key, err)
+			continue
+		}
+		if locked {
+			utils.RecordAudit(c, "auth.lockout", "login", failure.key, map[string]interface{}{
+				"scope":        failure.scope,
+				"locked_until": now.Add(failure.throttle.LockoutDuration),
+			})
+		}
+	}
+}
+
+func ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Send in the background so the response time does not reveal whether
+	// the account exists
+	go func(email string) {
+		var user models.User
+		if err := config.DB.Where("email = ? AND is_active = ?", email, true).First(&user).Error; err != nil {
+			return
+		}
+		if err := sendPasswordReset(user); err != nil {
+			log.Printf("Failed to send password reset to user %s: %v", user.ID, err)
+		}
+	}(input.Email)
+
+	utils.SuccessResponse(c, http.StatusOK, "If an account exists for that email, a password reset link has been sent", nil)
+}
+
+func ResetPassword(c *gin.Context) {
+	var input ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var reset models.VerificationToken
+	if err := config.DB.Where("token_hash = ? AND purpose = ? AND consumed_at IS NULL", utils.HashToken(input.Token), models.PurposePasswordReset).
+		First(&reset).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or already used reset token")
+		return
+	}
+
+	if time.Now().After(reset.ExpiresAt) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Reset token has expired")
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to hash password")
+		return
+	}
+
+	now := time.Now()
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the token atomically so it can only be used once
+		result := tx.Model(&models.VerificationToken{}).
+			Where("id = ? AND consumed_at IS NULL", reset.ID).
+			Update("consumed_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errResetTokenUsed
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", reset.UserID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"password_hashed":         hashedPassword,
+			"password_reset_required": false,
+			"session_version":         gorm.Expr("session_version + 1"),
+		}
+		// Receiving the link proves control of the mailbox
+		if user.EmailVerifiedAt == nil && user.Email == reset.Target {
+			updates["email_verified_at"] = now
+		}
+
+		return tx.Model(&user).Updates(updates).Error
+	})
+	if errors.Is(err, errResetTokenUsed) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or already used reset token")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+
+	utils.RecordAudit(c, "user.password_reset", "user", reset.UserID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Password has been reset, please log in again", nil)
+}
+
+func sendPasswordReset(user models.User) error {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	if err := issueVerificationToken(user, models.PurposePasswordReset, user.Email, token, passwordResetTTL); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppConfig.FrontendURL, token)
+	body := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in 1 hour. If you did not request this, you can ignore this email.", user.Name, link)
+	return utils.Mailer.SendEmail(user.Email, "Reset your password", body)
+}
@@ -1,6 +1,9 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
@@ -9,16 +12,27 @@ import (
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CreateBookingInput struct {
-	ServiceID     uuid.UUID `json:"service_id" binding:"required"`
-	ScheduledAt   time.Time `json:"scheduled_at" binding:"required"`
-	DurationHours float64   `json:"duration_hours" binding:"required,min=1"`
-	Address       string    `json:"address" binding:"required"`
-	Notes         string    `json:"notes"`
+	ServiceID       uuid.UUID            `json:"service_id" binding:"required"`
+	ScheduledAt     time.Time            `json:"scheduled_at" binding:"required"`
+	DurationMinutes int                  `json:"duration_minutes" binding:"required,min=1"`
+	AddressID       *uuid.UUID           `json:"address_id"` // saved address; takes precedence over address
+	Address         string               `json:"address" binding:"required_without=AddressID"`
+	Notes           string               `json:"notes"`
+	Options         []BookingOptionInput `json:"options" binding:"max=50,dive"`
+	// A favorite worker to offer the booking to first
+	PreferredWorkerID *uuid.UUID `json:"preferred_worker_id"`
 }
 
+var (
+	errBookingNotPending  = errors.New("booking is not pending")
+	errBookingReserved    = errors.New("booking is reserved for another worker")
+	errServiceUnavailable = errors.New("service is no longer active")
+)
+
 type CancelBookingInput struct {
 	Reason string `json:"reason"`
 }
@@ -40,36 +54,159 @@ func CreateBooking(c *gin.Context) {
 
 	// Get service to calculate price
 	var service models.Service
-	if err := config.DB.First(&service, "id = ?", input.ServiceID).Error; err != nil {
+	if err := config.DB.First(&service, "id = ? AND is_active = ?", input.ServiceID, true).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Service not found")
 		return
 	}
 
-	// Calculate total price
-	totalPrice := service.BasePrice + (service.PricePerHour * input.DurationHours)
+	if !service.AllowsDuration(input.DurationMinutes) {
+		utils.ErrorResponsef(c, http.StatusBadRequest, "Duration must be between %d and %d minutes in steps of %d minutes",
+			service.MinDuration, service.MaxDuration, service.DurationStep)
+		return
+	}
+
+	// Snapshot the saved address so later edits do not change this booking.
+	// The region that scopes staff access comes from the address's city,
+	// never from the client; free-text addresses have no region and are
+	// only visible to unscoped staff.
+	var details models.BookingAddress
+	address := input.Address
+	region := ""
+	if input.AddressID != nil {
+		var saved models.Address
+		if err := config.DB.First(&saved, "id = ? AND user_id = ?", *input.AddressID, userID).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Address not found")
+			return
+		}
+		details = saved.Snapshot()
+		address = details.Formatted()
+		region = saved.City
+	}
+
+	options, optionsPrice, message := priceBookingOptions(service.ID, input.Options)
+	if message != nil {
+		utils.ErrorResponsef(c, http.StatusBadRequest, message.Format, message.Args...)
+		return
+	}
+
+	// Calculate total price; discounted options cannot make it negative
+	totalPrice := service.BasePrice + (service.PricePerHour * float64(input.DurationMinutes) / 60) + optionsPrice
+	if totalPrice < 0 {
+		totalPrice = 0
+	}
+
+	var preferredWorker *models.Worker
+	var preferredUntil *time.Time
+	if input.PreferredWorkerID != nil {
+		var worker models.Worker
+		err := config.DB.Preload("User").
+			Joins("JOIN worker_preferences wp ON wp.worker_id = workers.id AND wp.customer_id = ? AND wp.kind = ?",
+				userID, models.PreferenceFavorite).
+			First(&worker, "workers.id = ?", *input.PreferredWorkerID).Error
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Preferred worker must be one of your favorites")
+			return
+		}
+		if !worker.IsAvailable || !worker.User.IsActive {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Preferred worker is not available")
+			return
+		}
+		var offers int64
+		if err := config.DB.Table("worker_services").
+			Where("worker_id = ? AND service_id = ?", worker.ID, service.ID).
+			Count(&offers).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create booking")
+			return
+		}
+		if offers == 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Preferred worker does not offer this service")
+			return
+		}
+		// The reservation never outlasts the start time
+		until := time.Now().Add(time.Duration(config.AppConfig.PreferredWorkerMinutes) * time.Minute)
+		if input.ScheduledAt.Before(until) {
+			until = input.ScheduledAt
+		}
+		preferredWorker, preferredUntil = &worker, &until
+	}
+
+	region, err := utils.NormalizeScopeValue(region)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create booking")
+		return
+	}
 
 	booking := models.Booking{
-		CustomerID:    userID,
-		ServiceID:     input.ServiceID,
-		ScheduledAt:   input.ScheduledAt,
-		DurationHours: input.DurationHours,
-		Address:       input.Address,
-		Notes:         input.Notes,
-		TotalPrice:    totalPrice,
-		Status:        models.StatusPending,
+		CustomerID:        userID,
+		ServiceID:         input.ServiceID,
+		ScheduledAt:       input.ScheduledAt,
+		DurationMinutes:   input.DurationMinutes,
+		Address:           address,
+		AddressID:         input.AddressID,
+		AddressDetails:    details,
+		Region:            region,
+		Notes:             input.Notes,
+		PreferredWorkerID: input.PreferredWorkerID,
+		PreferredUntil:    preferredUntil,
+		Options:           options,
+		OptionsPrice:      optionsPrice,
+		TotalPrice:        totalPrice,
+		Status:            models.StatusPending,
 	}
 
-	if err := config.DB.Create(&booking).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Hold the service so it cannot be deactivated or deleted until the
+		// booking is in place, then make sure it still can be booked
+		var active []bool
+		if err := tx.Raw("SELECT is_active FROM services WHERE id = ? AND deleted_at IS NULL FOR SHARE", service.ID).
+			Scan(&active).Error; err != nil {
+			return err
+		}
+		if len(active) == 0 || !active[0] {
+			return errServiceUnavailable
+		}
+		return tx.Create(&booking).Error
+	})
+	if errors.Is(err, errServiceUnavailable) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Service not found")
+		return
+	}
+	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create booking")
 		return
 	}
 
+	utils.AuditChange(c, "booking.created", "booking", booking.ID.String(), nil, booking)
+
+	if preferredWorker != nil {
+		if err := sendPreferredBookingOffer(*preferredWorker, booking); err != nil {
+			log.Printf("Failed to notify worker %s of booking %s: %v", preferredWorker.ID, booking.ID, err)
+		}
+	}
+
 	// Reload with relations
 	config.DB.Preload("Service").Preload("Customer").First(&booking, "id = ?", booking.ID)
 
 	utils.SuccessResponse(c, http.StatusCreated, "Booking created", booking)
 }
 
+var bookingSorts = map[string]utils.SortField{
+	"created_at":   {Column: "created_at", Type: utils.SortTime},
+	"scheduled_at": {Column: "scheduled_at", Type: utils.SortTime},
+	"total_price":  {Column: "total_price", Type: utils.SortNumber},
+}
+
+var bookingStatusValues = []string{
+	string(models.StatusPending),
+	string(models.StatusConfirmed),
+	string(models.StatusInProgress),
+	string(models.StatusCompleted),
+	string(models.StatusCancelled),
+}
+
+// GetBookings lists the caller's bookings, newest first by default.
+// Filters: status (comma-separated), service_id, and from/to on the
+// scheduled time. Sort by created_at, scheduled_at or total_price.
 func GetBookings(c *gin.Context) {
 	userID := c.MustGet("userID").(uuid.UUID)
 	userRole := c.MustGet("userRole").(string)
@@ -87,22 +224,89 @@ func GetBookings(c *gin.Context) {
 			return
 		}
 		query = query.Where("worker_id = ?", worker.ID)
+	} else {
+		// Staff only see the bookings their roles cover
+		permissions, err := utils.CurrentPermissions(c)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load permissions")
+			return
+		}
+		var ok bool
+		query, ok = permissions.ApplyScope(query, models.PermBookingsRead, bookingScopeColumns)
+		if !ok {
+			utils.ErrorResponse(c, http.StatusForbidden, "Access denied")
+			return
+		}
 	}
 
-	// Optional status filter
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+	page, err := utils.ParsePageQuery(c, bookingSorts, "-created_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+	statuses, err := utils.ParseSet(c, "status", bookingStatusValues)
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	query, ok := applyScheduleFilters(c, query)
+	if !ok {
+		return
 	}
 
-	if err := query.Order("created_at DESC").Find(&bookings).Error; err != nil {
+	if err := page.Apply(query).Find(&bookings).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch bookings")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Bookings retrieved", bookings)
+	bookings, meta := utils.Page(page, bookings, bookingPageKey(page))
+	utils.PagedResponse(c, http.StatusOK, "Bookings retrieved", bookings, meta)
+}
+
+// applyScheduleFilters applies the service_id and from/to filters shared by
+// the booking lists.
+func applyScheduleFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if serviceID := c.Query("service_id"); serviceID != "" {
+		id, err := uuid.Parse(serviceID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid service ID")
+			return nil, false
+		}
+		query = query.Where("service_id = ?", id)
+	}
+
+	from, to, err := utils.ParseTimeRange(c, "from", "to")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return nil, false
+	}
+	if from != nil {
+		query = query.Where("scheduled_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("scheduled_at < ?", *to)
+	}
+	return query, true
+}
+
+func bookingPageKey(page *utils.PageQuery) func(models.Booking) (interface{}, uuid.UUID) {
+	return func(b models.Booking) (interface{}, uuid.UUID) {
+		switch page.SortKey {
+		case "scheduled_at":
+			return b.ScheduledAt, b.ID
+		case "total_price":
+			return b.TotalPrice, b.ID
+		}
+		return b.CreatedAt, b.ID
+	}
 }
 
 func GetBookingByID(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
 	id := c.Param("id")
 	bookingID, err := uuid.Parse(id)
 	if err != nil {
@@ -111,26 +315,68 @@ func GetBookingByID(c *gin.Context) {
 	}
 
 	var booking models.Booking
-	if err := config.DB.Preload("Service").Preload("Customer").Preload("Worker.User").First(&booking, "id = ?", bookingID).Error; err != nil {
+	if err := config.DB.Preload("Service").Preload("Customer").Preload("Worker.User").
+		Preload("Checkpoints", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Checkpoints.Photos").
+		First(&booking, "id = ?", bookingID).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
 		return
 	}
 
+	if !canAccessBooking(c, userID, booking, models.PermBookingsRead) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Not authorized to view this booking")
+		return
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Booking retrieved", booking)
 }
 
+// PendingBooking is a booking open to workers, with the customer's
+// reliability to help them decide.
+type PendingBooking struct {
+	models.Booking
+	CustomerReliability CustomerReliability `json:"customer_reliability"`
+}
+
+// GetPendingBookings lists unassigned bookings the worker can accept,
+// soonest first by default. Bookings from customers who blocked the worker
+// and bookings reserved for another worker are left out.
 func GetPendingBookings(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
 	var bookings []models.Booking
+	query := config.DB.Preload("Service").Preload("Customer").
+		Where("status = ? AND worker_id IS NULL", models.StatusPending)
+	query = openToWorker(query, worker.ID, time.Now())
+
+	page, err := utils.ParsePageQuery(c, bookingSorts, "scheduled_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+	query, ok = applyScheduleFilters(c, query)
+	if !ok {
+		return
+	}
 
-	if err := config.DB.Preload("Service").Preload("Customer").
-		Where("status = ? AND worker_id IS NULL", models.StatusPending).
-		Order("scheduled_at ASC").
-		Find(&bookings).Error; err != nil {
+	if err := page.Apply(query).Find(&bookings).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch bookings")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Pending bookings retrieved", bookings)
+	bookings, meta := utils.Page(page, bookings, bookingPageKey(page))
+	pending := make([]PendingBooking, len(bookings))
+	for i, booking := range bookings {
+		// The street, exact location and access notes wait until a worker
+		// is assigned
+		booking.AddressDetails = booking.AddressDetails.Approximate()
+		booking.Address = booking.AddressDetails.Formatted()
+		pending[i] = PendingBooking{Booking: booking, CustomerReliability: customerReliability(booking.Customer)}
+	}
+	utils.PagedResponse(c, http.StatusOK, "Pending bookings retrieved", pending, meta)
 }
 
 func AcceptBooking(c *gin.Context) {
@@ -150,101 +396,123 @@ func AcceptBooking(c *gin.Context) {
 		return
 	}
 
-	var booking models.Booking
-	if err := config.DB.First(&booking, "id = ?", bookingID).Error; err != nil {
+	// Lock the booking so two workers cannot accept it at the same time
+	var booking, before models.Booking
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM bookings WHERE id = ? FOR UPDATE", bookingID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&booking, "id = ?", bookingID).Error; err != nil {
+			return err
+		}
+
+		// Blocked workers must not learn the booking exists
+		blocked, err := customerBlockedWorker(tx, booking.CustomerID, worker.ID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return gorm.ErrRecordNotFound
+		}
+
+		if booking.Status != models.StatusPending || booking.WorkerID != nil {
+			return errBookingNotPending
+		}
+		if booking.PreferredWorkerID != nil && *booking.PreferredWorkerID != worker.ID &&
+			booking.PreferredUntil != nil && booking.PreferredUntil.After(time.Now()) {
+			return errBookingReserved
+		}
+
+		before = booking
+		now := time.Now()
+		booking.WorkerID = &worker.ID
+		booking.Status = models.StatusConfirmed
+		booking.AcceptedAt = &now
+		return tx.Save(&booking).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
 		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
 		return
-	}
-
-	if booking.Status != models.StatusPending {
+	case errors.Is(err, errBookingNotPending):
 		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not pending")
 		return
-	}
-
-	booking.WorkerID = &worker.ID
-	booking.Status = models.StatusConfirmed
-
-	if err := config.DB.Save(&booking).Error; err != nil {
+	case errors.Is(err, errBookingReserved):
+		utils.ErrorResponse(c, http.StatusConflict, "Booking is reserved for another worker")
+		return
+	case err != nil:
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to accept booking")
 		return
 	}
 
+	utils.AuditChange(c, "booking.accepted", "booking", booking.ID.String(), before, booking)
+
 	config.DB.Preload("Service").Preload("Customer").Preload("Worker.User").First(&booking, "id = ?", bookingID)
 	utils.SuccessResponse(c, http.StatusOK, "Booking accepted", booking)
 }
 
-func StartBooking(c *gin.Context) {
-	userID := c.MustGet("userID").(uuid.UUID)
-
-	id := c.Param("id")
-	bookingID, err := uuid.Parse(id)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID")
+// DeclineBooking lets the preferred worker pass on a booking reserved for
+// them, opening it to every worker straight away.
+func DeclineBooking(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
 		return
 	}
 
-	var worker models.Worker
-	if err := config.DB.First(&worker, "user_id = ?", userID).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Worker profile not found")
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID")
 		return
 	}
 
 	var booking models.Booking
-	if err := config.DB.First(&booking, "id = ? AND worker_id = ?", bookingID, worker.ID).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found or not assigned to you")
+	if err := config.DB.First(&booking, "id = ? AND preferred_worker_id = ?", bookingID, worker.ID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
 		return
 	}
-
-	if booking.Status != models.StatusConfirmed {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not confirmed")
+	if booking.Status != models.StatusPending || booking.WorkerID != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not pending")
 		return
 	}
 
+	before := booking
 	now := time.Now()
-	booking.Status = models.StatusInProgress
-	booking.StartedAt = &now
-
-	if err := config.DB.Save(&booking).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start booking")
-		return
+	if booking.PreferredUntil == nil || booking.PreferredUntil.After(now) {
+		booking.PreferredUntil = &now
+		if err := config.DB.Model(&booking).Update("preferred_until", now).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to decline booking")
+			return
+		}
+		utils.AuditChange(c, "booking.declined", "booking", booking.ID.String(), before, booking)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Booking started", booking)
+	utils.SuccessResponse(c, http.StatusOK, "Booking declined", nil)
 }
 
-func CompleteBooking(c *gin.Context) {
-	userID := c.MustGet("userID").(uuid.UUID)
-
-	id := c.Param("id")
-	bookingID, err := uuid.Parse(id)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID")
-		return
-	}
-
-	var worker models.Worker
-	if err := config.DB.First(&worker, "user_id = ?", userID).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Worker profile not found")
-		return
-	}
-
-	var booking models.Booking
-	if err := config.DB.First(&booking, "id = ? AND worker_id = ?", bookingID, worker.ID).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found or not assigned to you")
-		return
-	}
+func sendPreferredBookingOffer(worker models.Worker, booking models.Booking) error {
+	when := booking.ScheduledAt.In(config.AppConfig.Location).Format("Mon 2 Jan 2006 15:04")
+	deadline := booking.PreferredUntil.In(config.AppConfig.Location).Format("15:04")
+	body := fmt.Sprintf("Hi %s,\n\nA customer who marked you as a favorite has requested you for a booking on %s.\n\n"+
+		"It is reserved for you until %s; after that other workers can accept it.", worker.User.Name, when, deadline)
+	return utils.Mailer.SendEmail(worker.User.Email, "A customer requested you", body)
+}
 
-	if booking.Status != models.StatusInProgress {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not in progress")
+// StartBooking checks the worker in. The request may carry evidence, see
+// parseCheckpointInput; a reported location must be near the booking address.
+func StartBooking(c *gin.Context) {
+	booking, _, ok := advanceBooking(c, models.CheckpointCheckIn)
+	if !ok {
 		return
 	}
 
-	now := time.Now()
-	booking.Status = models.StatusCompleted
-	booking.CompletedAt = &now
+	utils.SuccessResponse(c, http.StatusOK, "Booking started", booking)
+}
 
-	if err := config.DB.Save(&booking).Error; err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to complete booking")
+// CompleteBooking checks the worker out, with the same optional evidence as
+// StartBooking. The location is recorded but not enforced.
+func CompleteBooking(c *gin.Context) {
+	booking, worker, ok := advanceBooking(c, models.CheckpointCheckOut)
+	if !ok {
 		return
 	}
 
@@ -275,11 +543,7 @@ func CancelBooking(c *gin.Context) {
 		return
 	}
 
-	// Check ownership (customer or assigned worker)
-	var worker models.Worker
-	isWorker := config.DB.First(&worker, "user_id = ?", userID).Error == nil
-
-	if booking.CustomerID != userID && (!isWorker || booking.WorkerID == nil || *booking.WorkerID != worker.ID) {
+	if !canAccessBooking(c, userID, booking, models.PermBookingsManage) {
 		utils.ErrorResponse(c, http.StatusForbidden, "Not authorized to cancel this booking")
 		return
 	}
@@ -289,6 +553,7 @@ func CancelBooking(c *gin.Context) {
 		return
 	}
 
+	before := booking
 	now := time.Now()
 	booking.Status = models.StatusCancelled
 	booking.CancelledAt = &now
@@ -299,5 +564,31 @@ func CancelBooking(c *gin.Context) {
 		return
 	}
 
+	utils.AuditChange(c, "booking.cancelled", "booking", booking.ID.String(), before, booking)
+
 	utils.SuccessResponse(c, http.StatusOK, "Booking cancelled", booking)
 }
+
+// bookingScopeColumns maps role scope attributes to booking columns.
+var bookingScopeColumns = map[string]string{"region": "region"}
+
+// canAccessBooking allows the customer, the assigned worker and staff holding
+// perm in a scope that covers the booking.
+func canAccessBooking(c *gin.Context, userID uuid.UUID, booking models.Booking, perm models.Permission) bool {
+	if booking.CustomerID == userID {
+		return true
+	}
+
+	if booking.WorkerID != nil {
+		var worker models.Worker
+		if config.DB.First(&worker, "user_id = ?", userID).Error == nil && *booking.WorkerID == worker.ID {
+			return true
+		}
+	}
+
+	permissions, err := utils.CurrentPermissions(c)
+	if err != nil {
+		return false
+	}
+	return permissions.Allows(perm, map[string]string{"region": booking.Region})
+}
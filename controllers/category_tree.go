@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// visibleCategoriesSQL selects the categories shown publicly: active ones
+// whose ancestors are all active too.
+const visibleCategoriesSQL = `
+	WITH RECURSIVE visible AS (
+		SELECT id FROM service_categories
+		WHERE parent_id IS NULL AND is_active AND deleted_at IS NULL
+		UNION ALL
+		SELECT c.id FROM service_categories c
+		JOIN visible v ON c.parent_id = v.id
+		WHERE c.is_active AND c.deleted_at IS NULL
+	)
+	SELECT id FROM visible`
+
+// categoryAncestors returns the path from the root down to the category,
+// the category itself included.
+func categoryAncestors(db *gorm.DB, id uuid.UUID) ([]models.ServiceCategory, error) {
+	var path []models.ServiceCategory
+	err := db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT *, 0 AS distance FROM service_categories WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.*, a.distance + 1 FROM service_categories c
+			JOIN ancestors a ON c.id = a.parent_id
+			WHERE c.deleted_at IS NULL AND a.distance < ?
+		)
+		SELECT * FROM ancestors ORDER BY distance DESC`, id, models.MaxCategoryDepth*2).
+		Scan(&path).Error
+	return path, err
+}
+
+// categorySubtreeIDs returns the category and all of its descendants.
+func categorySubtreeIDs(db *gorm.DB, id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id, 0 AS depth FROM service_categories WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, s.depth + 1 FROM service_categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_at IS NULL AND s.depth < ?
+		)
+		SELECT id FROM subtree`, id, models.MaxCategoryDepth*2).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// categorySubtreeHeight is the number of levels from the category down to
+// its deepest descendant, the category itself counting as one.
+func categorySubtreeHeight(db *gorm.DB, id uuid.UUID) (int, error) {
+	var height int
+	err := db.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id, 1 AS level FROM service_categories WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, s.level + 1 FROM service_categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_at IS NULL AND s.level <= ?
+		)
+		SELECT COALESCE(MAX(level), 0) FROM subtree`, id, models.MaxCategoryDepth*2).
+		Scan(&height).Error
+	return height, err
+}
+
+// buildCategoryTree nests a flat, ordered list of categories under their
+// parents. Categories whose parent is not in the list are dropped.
+func buildCategoryTree(flat []models.ServiceCategory) []models.ServiceCategory {
+	children := map[uuid.UUID][]models.ServiceCategory{}
+	var roots []models.ServiceCategory
+	for _, category := range flat {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(nodes []models.ServiceCategory) []models.ServiceCategory
+	attach = func(nodes []models.ServiceCategory) []models.ServiceCategory {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	if roots == nil {
+		return []models.ServiceCategory{}
+	}
+	return attach(roots)
+}
+
+// validCategoryParent checks that placing a subtree of the given height under
+// parentID keeps the tree within MaxCategoryDepth and, when moving an
+// existing category, does not put it underneath itself.
+func validCategoryParent(parentID uuid.UUID, moving *uuid.UUID, height int) (bool, string) {
+	path, err := categoryAncestors(config.DB, parentID)
+	if err != nil || len(path) == 0 {
+		return false, "Parent category not found"
+	}
+
+	if moving != nil {
+		for _, ancestor := range path {
+			if ancestor.ID == *moving {
+				return false, "A category cannot be moved under itself or its subcategories"
+			}
+		}
+	}
+
+	if len(path)+height > models.MaxCategoryDepth {
+		return false, "Categories cannot be nested that deeply"
+	}
+	return true, ""
+}
@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCheckpointPhotos = 5
+	maxCheckpointNote   = 1000
+	earthRadiusMeters   = 6371000
+)
+
+var (
+	errWrongBookingStatus = errors.New("booking is not in the expected status")
+	errTooFarFromAddress  = errors.New("too far from the booking address")
+	errLocationRequired   = errors.New("location is required to check in")
+)
+
+// checkpointInput is the evidence sent with a check-in or check-out. All of
+// it is optional, except the location when checking in at an address with
+// coordinates.
+type checkpointInput struct {
+	Latitude  *float64                `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64                `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	Accuracy  *float64                `json:"accuracy_meters" binding:"omitempty,min=0"`
+	Note      string                  `json:"note"`
+	Photos    []*multipart.FileHeader `json:"-" form:"-"`
+}
+
+// TimelineEntry is one event in a booking's history.
+type TimelineEntry struct {
+	Type       string                    `json:"type"` // created, accepted, check_in, check_out, photo, cancelled
+	At         time.Time                 `json:"at"`
+	Reason     string                    `json:"reason,omitempty"`
+	Checkpoint *models.BookingCheckpoint `json:"checkpoint,omitempty"`
+	Photo      *models.Media             `json:"photo,omitempty"`
+}
+
+// GetBookingTimeline lists what happened to a booking, oldest first, with
+// the worker's check-in and check-out evidence. It is shown to the
+// customer, the assigned worker and support staff.
+func GetBookingTimeline(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID")
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.Preload("Checkpoints.Photos").First(&booking, "id = ?", bookingID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
+		return
+	}
+	if !canAccessBooking(c, userID, booking, models.PermBookingsRead) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Not authorized to view this booking")
+		return
+	}
+
+	var photos []models.Media
+	if err := config.DB.Where("booking_id = ? AND checkpoint_id IS NULL", booking.ID).Find(&photos).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch timeline")
+		return
+	}
+
+	timeline := []TimelineEntry{{Type: "created", At: booking.CreatedAt}}
+	if booking.AcceptedAt != nil {
+		timeline = append(timeline, TimelineEntry{Type: "accepted", At: *booking.AcceptedAt})
+	}
+	for i := range booking.Checkpoints {
+		checkpoint := &booking.Checkpoints[i]
+		timeline = append(timeline, TimelineEntry{Type: string(checkpoint.Kind), At: checkpoint.CreatedAt, Checkpoint: checkpoint})
+	}
+	for i := range photos {
+		timeline = append(timeline, TimelineEntry{Type: "photo", At: photos[i].CreatedAt, Photo: &photos[i]})
+	}
+	if booking.CancelledAt != nil {
+		timeline = append(timeline, TimelineEntry{Type: "cancelled", At: *booking.CancelledAt, Reason: booking.CancelReason})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].At.Before(timeline[j].At) })
+
+	utils.SuccessResponse(c, http.StatusOK, "Timeline retrieved", timeline)
+}
+
+// advanceBooking moves the worker's booking from confirmed to in progress
+// (check-in) or from in progress to completed (check-out), recording the
+// evidence. It writes the error response and returns false on failure.
+func advanceBooking(c *gin.Context, kind models.CheckpointKind) (models.Booking, models.Worker, bool) {
+	var booking models.Booking
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID")
+		return booking, models.Worker{}, false
+	}
+	worker, ok := currentWorker(c)
+	if !ok {
+		return booking, worker, false
+	}
+	input, ok := parseCheckpointInput(c)
+	if !ok {
+		return booking, worker, false
+	}
+
+	from, to := models.StatusConfirmed, models.StatusInProgress
+	if kind == models.CheckpointCheckOut {
+		from, to = models.StatusInProgress, models.StatusCompleted
+	}
+
+	var before models.Booking
+	var photos []models.Media
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM bookings WHERE id = ? FOR UPDATE", bookingID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&booking, "id = ? AND worker_id = ?", bookingID, worker.ID).Error; err != nil {
+			return err
+		}
+		if booking.Status != from {
+			return errWrongBookingStatus
+		}
+
+		checkpoint := models.BookingCheckpoint{
+			BookingID: booking.ID,
+			Kind:      kind,
+			WorkerID:  worker.ID,
+			Latitude:  input.Latitude,
+			Longitude: input.Longitude,
+			Accuracy:  input.Accuracy,
+			Note:      input.Note,
+		}
+		checkpoint.DistanceMeters = distanceFromAddress(booking, input)
+		if kind == models.CheckpointCheckIn {
+			address := booking.AddressDetails
+			if input.Latitude == nil && address.Latitude != nil && address.Longitude != nil {
+				return errLocationRequired
+			}
+			if !withinCheckinRadius(checkpoint) {
+				return errTooFarFromAddress
+			}
+		}
+		if err := tx.Create(&checkpoint).Error; err != nil {
+			return err
+		}
+
+		maxBytes := int64(config.AppConfig.MaxUploadMB) << 20
+		for _, header := range input.Photos {
+			file, err := header.Open()
+			if err != nil {
+				return err
+			}
+			photo := models.Media{
+				OwnerID:      worker.UserID,
+				Purpose:      models.MediaJobPhoto,
+				BookingID:    &booking.ID,
+				CheckpointID: &checkpoint.ID,
+			}
+			err = utils.StoreImage(tx, io.LimitReader(file, maxBytes), &photo)
+			file.Close()
+			photos = append(photos, photo)
+			if err != nil {
+				return err
+			}
+		}
+
+		before = booking
+		now := time.Now()
+		booking.Status = to
+		if kind == models.CheckpointCheckIn {
+			booking.StartedAt = &now
+		} else {
+			booking.CompletedAt = &now
+		}
+		return tx.Omit("Checkpoints").Save(&booking).Error
+	})
+	if err != nil {
+		for _, photo := range photos {
+			if err := utils.DiscardUnusedBlobs(photo); err != nil {
+				log.Printf("Failed to discard blobs of %s: %v", photo.SHA256, err)
+			}
+		}
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found or not assigned to you")
+		return booking, worker, false
+	case errors.Is(err, errWrongBookingStatus) && kind == models.CheckpointCheckIn:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not confirmed")
+		return booking, worker, false
+	case errors.Is(err, errWrongBookingStatus):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Booking is not in progress")
+		return booking, worker, false
+	case errors.Is(err, errTooFarFromAddress):
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "You must be at the booking address to check in")
+		return booking, worker, false
+	case errors.Is(err, errLocationRequired):
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Location is required to check in")
+		return booking, worker, false
+	case errors.Is(err, utils.ErrUnsupportedImage), errors.Is(err, utils.ErrImageTooLarge):
+		imageErrorResponse(c, err)
+		return booking, worker, false
+	case err != nil && kind == models.CheckpointCheckIn:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start booking")
+		return booking, worker, false
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to complete booking")
+		return booking, worker, false
+	}
+
+	config.DB.Preload("Checkpoints", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Checkpoints.Photos").First(&booking, "id = ?", booking.ID)
+
+	action := "booking.started"
+	if kind == models.CheckpointCheckOut {
+		action = "booking.completed"
+	}
+	utils.AuditChangeWithMetadata(c, action, "booking", booking.ID.String(), before, booking,
+		map[string]interface{}{"photos": len(input.Photos), "has_location": input.Latitude != nil})
+	return booking, worker, true
+}
+
+// parseCheckpointInput reads evidence from a multipart form (fields
+// latitude, longitude, accuracy_meters, note and up to five "photos" files)
+// or from a JSON body without photos. An empty body is accepted.
+func parseCheckpointInput(c *gin.Context) (checkpointInput, bool) {
+	var input checkpointInput
+
+	switch c.ContentType() {
+	case "multipart/form-data":
+		maxBytes := int64(config.AppConfig.MaxUploadMB) << 20
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCheckpointPhotos*maxBytes+1<<20)
+		form, err := c.MultipartForm()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.ErrorResponsef(c, http.StatusRequestEntityTooLarge, "File must not exceed %d MB", config.AppConfig.MaxUploadMB)
+			} else {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid form data")
+			}
+			return input, false
+		}
+
+		for field, target := range map[string]**float64{"latitude": &input.Latitude, "longitude": &input.Longitude, "accuracy_meters": &input.Accuracy} {
+			raw := c.PostForm(field)
+			if raw == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Location and accuracy must be numbers")
+				return input, false
+			}
+			*target = &value
+		}
+		input.Note = c.PostForm("note")
+
+		input.Photos = form.File["photos"]
+		if len(input.Photos) > maxCheckpointPhotos {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Too many photos attached")
+			return input, false
+		}
+		for _, photo := range input.Photos {
+			if photo.Size > maxBytes {
+				utils.ErrorResponsef(c, http.StatusRequestEntityTooLarge, "File must not exceed %d MB", config.AppConfig.MaxUploadMB)
+				return input, false
+			}
+		}
+	case "application/json":
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return input, false
+		}
+	}
+
+	input.Note = strings.TrimSpace(input.Note)
+	switch {
+	case (input.Latitude == nil) != (input.Longitude == nil):
+		utils.ErrorResponse(c, http.StatusBadRequest, "latitude and longitude must be sent together")
+		return input, false
+	case input.Latitude != nil && (math.Abs(*input.Latitude) > 90 || math.Abs(*input.Longitude) > 180):
+		utils.ErrorResponse(c, http.StatusBadRequest, "latitude and longitude must be valid coordinates")
+		return input, false
+	case input.Accuracy != nil && *input.Accuracy < 0:
+		utils.ErrorResponse(c, http.StatusBadRequest, "accuracy_meters must not be negative")
+		return input, false
+	case len([]rune(input.Note)) > maxCheckpointNote:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Note is too long")
+		return input, false
+	}
+	return input, true
+}
+
+func distanceFromAddress(booking models.Booking, input checkpointInput) *float64 {
+	address := booking.AddressDetails
+	if input.Latitude == nil || address.Latitude == nil || address.Longitude == nil {
+		return nil
+	}
+	distance := math.Round(haversineMeters(*input.Latitude, *input.Longitude, *address.Latitude, *address.Longitude))
+	return &distance
+}
+
+// withinCheckinRadius allows check-ins within CHECKIN_RADIUS_METERS of the
+// booking address, plus the device's reported accuracy up to the same
+// radius again. Check-ins at an address without coordinates cannot be
+// checked and are allowed; elsewhere advanceBooking requires a location.
+func withinCheckinRadius(checkpoint models.BookingCheckpoint) bool {
+	if checkpoint.DistanceMeters == nil {
+		return true
+	}
+	radius := float64(config.AppConfig.CheckinRadiusMeters)
+	slack := 0.0
+	if checkpoint.Accuracy != nil {
+		slack = math.Min(*checkpoint.Accuracy, radius)
+	}
+	return *checkpoint.DistanceMeters <= radius+slack
+}
+
+// haversineMeters is the great-circle distance between two points.
+func haversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errAlreadyReviewed = errors.New("booking already reviewed")
+
+// CustomerReliability is how workers rated a customer. It is shown to
+// workers deciding on a booking and to admins, never on the user itself.
+type CustomerReliability struct {
+	Score   float64 `json:"score"` // average rating, 0 until rated
+	Reviews int     `json:"reviews"`
+}
+
+func customerReliability(user models.User) CustomerReliability {
+	return CustomerReliability{Score: user.ReliabilityScore, Reviews: user.ReliabilityReviews}
+}
+
+type CreateCustomerReviewInput struct {
+	BookingID uuid.UUID `json:"booking_id" binding:"required"`
+	Rating    int       `json:"rating" binding:"required,min=1,max=5"`
+	Comment   string    `json:"comment"`
+}
+
+// CreateCustomerReview lets the worker rate the customer of a booking they
+// completed, and updates the customer's reliability score.
+func CreateCustomerReview(c *gin.Context) {
+	var input CreateCustomerReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	if err := config.DB.First(&booking, "id = ? AND worker_id = ?", input.BookingID, worker.ID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
+		return
+	}
+	if booking.Status != models.StatusCompleted {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Can only review completed bookings")
+		return
+	}
+
+	review := models.CustomerReview{
+		BookingID:  booking.ID,
+		CustomerID: booking.CustomerID,
+		WorkerID:   worker.ID,
+		Rating:     input.Rating,
+		Comment:    input.Comment,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the customer so concurrent reviews aggregate correctly
+		if err := tx.Exec("SELECT id FROM users WHERE id = ? FOR UPDATE", booking.CustomerID).Error; err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&models.CustomerReview{}).Where("booking_id = ?", booking.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyReviewed
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return updateReliabilityScore(tx, booking.CustomerID)
+	})
+	if errors.Is(err, errAlreadyReviewed) {
+		utils.ErrorResponse(c, http.StatusConflict, "Booking already reviewed")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create review")
+		return
+	}
+
+	utils.AuditChange(c, "customer_review.created", "customer_review", review.ID.String(), nil, review)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Review submitted", review)
+}
+
+// updateReliabilityScore recomputes a customer's reliability score from the
+// ratings workers gave them.
+func updateReliabilityScore(tx *gorm.DB, customerID uuid.UUID) error {
+	var totals struct {
+		Average float64
+		Count   int
+	}
+	if err := tx.Model(&models.CustomerReview{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("customer_id = ?", customerID).
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", customerID).Updates(map[string]interface{}{
+		"reliability_score":   totals.Average,
+		"reliability_reviews": totals.Count,
+	}).Error
+}
+
+var customerReviewSorts = map[string]utils.SortField{
+	"created_at": {Column: "created_at", Type: utils.SortTime},
+	"rating":     {Column: "rating", Type: utils.SortNumber},
+}
+
+// AdminGetCustomerReviews lists what workers said about a customer, newest
+// first by default.
+func AdminGetCustomerReviews(c *gin.Context) {
+	user, ok := findUserForAdmin(c, true)
+	if !ok {
+		return
+	}
+
+	page, err := utils.ParsePageQuery(c, customerReviewSorts, "-created_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	var reviews []models.CustomerReview
+	query := config.DB.Where("customer_id = ?", user.ID).Preload("Worker.User")
+	if err := page.Apply(query).Find(&reviews).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch reviews")
+		return
+	}
+
+	reviews, meta := utils.Page(page, reviews, func(r models.CustomerReview) (interface{}, uuid.UUID) {
+		if page.SortKey == "rating" {
+			return r.Rating, r.ID
+		}
+		return r.CreatedAt, r.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Reviews retrieved", reviews, meta)
+}
+
+// AdminFlagUser marks a customer for attention, typically after a low
+// reliability score. The flag is visible only in the admin API.
+func AdminFlagUser(c *gin.Context) {
+	setUserFlag(c, true)
+}
+
+func AdminUnflagUser(c *gin.Context) {
+	setUserFlag(c, false)
+}
+
+func setUserFlag(c *gin.Context, flagged bool) {
+	var input AdminReasonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, ok := findUserForAdmin(c, false)
+	if !ok || !canManageUser(c, user) {
+		return
+	}
+
+	if (user.FlaggedAt != nil) == flagged {
+		utils.ErrorResponse(c, http.StatusBadRequest, "User is already in that state")
+		return
+	}
+
+	updates := map[string]interface{}{"flagged_at": nil, "flag_reason": ""}
+	action, message := "user.unflagged", "User unflagged"
+	if flagged {
+		updates = map[string]interface{}{"flagged_at": time.Now(), "flag_reason": input.Reason}
+		action, message = "user.flagged", "User flagged"
+	}
+	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	config.DB.First(&user, "id = ?", user.ID)
+	utils.RecordAudit(c, action, "user", user.ID.String(), map[string]interface{}{
+		"reason":              input.Reason,
+		"reliability_score":   user.ReliabilityScore,
+		"reliability_reviews": user.ReliabilityReviews,
+	})
+	utils.SuccessResponse(c, http.StatusOK, message, AdminUserFlag{FlaggedAt: user.FlaggedAt, Reason: user.FlagReason})
+}
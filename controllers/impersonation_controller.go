@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const defaultImpersonationMinutes = 15
+
+type StartImpersonationInput struct {
+	Reason          string `json:"reason" binding:"required,min=5,max=500"`
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1,max=60"`
+}
+
+type ImpersonationResponse struct {
+	Token   string                      `json:"token"`
+	Session models.ImpersonationSession `json:"session"`
+}
+
+func StartImpersonation(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input StartImpersonationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	minutes := input.DurationMinutes
+	if minutes == 0 {
+		minutes = defaultImpersonationMinutes
+	}
+
+	if userID == adminID {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot impersonate yourself")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	// Only end-user accounts; staff powers must never be borrowed this way
+	if user.Role != models.RoleCustomer && user.Role != models.RoleWorker {
+		utils.ErrorResponse(c, http.StatusForbidden, "Only customer and worker accounts can be impersonated")
+		return
+	}
+
+	if !user.IsActive {
+		utils.ErrorResponse(c, http.StatusBadRequest, "User account is deactivated")
+		return
+	}
+
+	session := models.ImpersonationSession{
+		ImpersonatorID: adminID,
+		UserID:         user.ID,
+		Reason:         input.Reason,
+		ExpiresAt:      time.Now().Add(time.Duration(minutes) * time.Minute),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start impersonation")
+		return
+	}
+
+	token, err := utils.GenerateImpersonationToken(user, adminID, session.ID, session.ExpiresAt)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.RecordAudit(c, "impersonation.started", "user", user.ID.String(), map[string]interface{}{
+		"session_id": session.ID,
+		"reason":     input.Reason,
+		"expires_at": session.ExpiresAt,
+	})
+
+	session.User = user
+	utils.SuccessResponse(c, http.StatusCreated, "Impersonation started", ImpersonationResponse{
+		Token:   token,
+		Session: session,
+	})
+}
+
+var impersonationSorts = map[string]utils.SortField{
+	"created_at": {Column: "created_at", Type: utils.SortTime},
+	"expires_at": {Column: "expires_at", Type: utils.SortTime},
+}
+
+func GetImpersonations(c *gin.Context) {
+	query := config.DB.Preload("Impersonator").Preload("User")
+
+	if c.Query("active") == "true" {
+		query = query.Where("ended_at IS NULL AND expires_at > ?", time.Now())
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	page, err := utils.ParsePageQuery(c, impersonationSorts, "-created_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := page.Apply(query).Find(&sessions).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch impersonation sessions")
+		return
+	}
+
+	sessions, meta := utils.Page(page, sessions, func(s models.ImpersonationSession) (interface{}, uuid.UUID) {
+		if page.SortKey == "expires_at" {
+			return s.ExpiresAt, s.ID
+		}
+		return s.CreatedAt, s.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Impersonation sessions retrieved", sessions, meta)
+}
+
+func EndImpersonation(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	endImpersonation(c, sessionID)
+}
+
+// EndCurrentImpersonation lets the impersonation token end its own session.
+func EndCurrentImpersonation(c *gin.Context) {
+	sessionID, ok := c.Get("impersonationSessionID")
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Not an impersonation session")
+		return
+	}
+
+	endImpersonation(c, sessionID.(uuid.UUID))
+}
+
+func endImpersonation(c *gin.Context, sessionID uuid.UUID) {
+	actorID := c.MustGet("userID").(uuid.UUID)
+	if impersonatorID, ok := c.Get("impersonatorID"); ok {
+		actorID = impersonatorID.(uuid.UUID)
+	}
+
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Impersonation session not found")
+		return
+	}
+
+	if !session.IsActive(time.Now()) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Impersonation session has already ended")
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&session).Updates(map[string]interface{}{
+		"ended_at": now,
+		"ended_by": actorID,
+	}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to end impersonation")
+		return
+	}
+
+	utils.RecordAudit(c, "impersonation.ended", "impersonation_session", session.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Impersonation ended", session)
+}
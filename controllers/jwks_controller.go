@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public token signing keys so other services can
+// verify our access tokens without sharing a secret.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.PublicJWKS())
+}
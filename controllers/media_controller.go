@@ -0,0 +1,380 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxBookingPhotos = 20
+
+// Images are uploaded as multipart forms with the image in the "file"
+// field. Responses link to them through signed URLs that expire after
+// MEDIA_URL_TTL_MINUTES.
+
+func UploadAvatar(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	media := models.Media{OwnerID: userID, Purpose: models.MediaAvatar}
+	ok := storeUploadedImage(c, &media, func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"avatar_media_id": media.ID, "avatar": ""}).Error
+	})
+	if !ok {
+		return
+	}
+	releaseReplacedMedia(user.AvatarMediaID)
+
+	before := user
+	config.DB.First(&user, "id = ?", userID)
+	utils.AuditChange(c, "user.avatar_updated", "user", userID.String(), before, user)
+	utils.SuccessResponse(c, http.StatusOK, "Avatar updated", user)
+}
+
+func DeleteAvatar(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"avatar_media_id": nil, "avatar": ""}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove image")
+		return
+	}
+	releaseReplacedMedia(user.AvatarMediaID)
+
+	before := user
+	config.DB.First(&user, "id = ?", userID)
+	utils.AuditChange(c, "user.avatar_removed", "user", userID.String(), before, user)
+	utils.SuccessResponse(c, http.StatusOK, "Avatar removed", user)
+}
+
+func UploadServiceImage(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	media := models.Media{OwnerID: c.MustGet("userID").(uuid.UUID), Purpose: models.MediaServiceImage}
+	ok = storeUploadedImage(c, &media, func(tx *gorm.DB) error {
+		return tx.Model(&models.Service{}).Where("id = ?", service.ID).
+			Updates(map[string]interface{}{"image_media_id": media.ID, "image": ""}).Error
+	})
+	if !ok {
+		return
+	}
+	releaseReplacedMedia(service.ImageMediaID)
+
+	before := service
+	config.DB.First(&service, "id = ?", service.ID)
+	utils.AuditChange(c, "service.image_updated", "service", service.ID.String(), before, service)
+	utils.SuccessResponse(c, http.StatusOK, "Image updated", service)
+}
+
+func DeleteServiceImage(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&models.Service{}).Where("id = ?", service.ID).
+		Updates(map[string]interface{}{"image_media_id": nil, "image": ""}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove image")
+		return
+	}
+	releaseReplacedMedia(service.ImageMediaID)
+
+	before := service
+	config.DB.First(&service, "id = ?", service.ID)
+	utils.AuditChange(c, "service.image_removed", "service", service.ID.String(), before, service)
+	utils.SuccessResponse(c, http.StatusOK, "Image removed", service)
+}
+
+func UploadCategoryIcon(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	media := models.Media{OwnerID: c.MustGet("userID").(uuid.UUID), Purpose: models.MediaCategoryIcon}
+	ok = storeUploadedImage(c, &media, func(tx *gorm.DB) error {
+		return tx.Model(&models.ServiceCategory{}).Where("id = ?", category.ID).
+			Updates(map[string]interface{}{"icon_media_id": media.ID, "icon": ""}).Error
+	})
+	if !ok {
+		return
+	}
+	releaseReplacedMedia(category.IconMediaID)
+
+	before := category
+	config.DB.First(&category, "id = ?", category.ID)
+	utils.AuditChange(c, "category.icon_updated", "category", category.ID.String(), before, category)
+	utils.SuccessResponse(c, http.StatusOK, "Icon updated", category)
+}
+
+func DeleteCategoryIcon(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&models.ServiceCategory{}).Where("id = ?", category.ID).
+		Updates(map[string]interface{}{"icon_media_id": nil, "icon": ""}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove image")
+		return
+	}
+	releaseReplacedMedia(category.IconMediaID)
+
+	before := category
+	config.DB.First(&category, "id = ?", category.ID)
+	utils.AuditChange(c, "category.icon_removed", "category", category.ID.String(), before, category)
+	utils.SuccessResponse(c, http.StatusOK, "Icon removed", category)
+}
+
+// UploadBookingPhoto attaches a job photo, e.g. of the work area before or
+// after the job. The customer, the assigned worker and staff who manage the
+// booking can add photos.
+func UploadBookingPhoto(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	booking, ok := findBookingForPhotos(c, userID, models.PermBookingsManage)
+	if !ok {
+		return
+	}
+	if booking.Status == models.StatusCancelled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Cannot add photos to a cancelled booking")
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Media{}).Where("booking_id = ?", booking.ID).Count(&count)
+	if count >= maxBookingPhotos {
+		utils.ErrorResponse(c, http.StatusBadRequest, "This booking already has the maximum number of photos")
+		return
+	}
+
+	media := models.Media{OwnerID: userID, Purpose: models.MediaJobPhoto, BookingID: &booking.ID}
+	if !storeUploadedImage(c, &media, nil) {
+		return
+	}
+
+	config.DB.First(&media, "id = ?", media.ID)
+	utils.AuditChange(c, "booking.photo_added", "booking", booking.ID.String(), nil, media)
+	utils.SuccessResponse(c, http.StatusCreated, "Photo uploaded", media)
+}
+
+func GetBookingPhotos(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	booking, ok := findBookingForPhotos(c, userID, models.PermBookingsRead)
+	if !ok {
+		return
+	}
+
+	var photos []models.Media
+	if err := config.DB.Where("booking_id = ?", booking.ID).Order("created_at ASC").Find(&photos).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch photos")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Photos retrieved", photos)
+}
+
+// DeleteBookingPhoto removes a photo. Only its uploader can, or staff who
+// manage the booking; check-in and check-out photos only staff.
+func DeleteBookingPhoto(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	booking, ok := findBookingForPhotos(c, userID, models.PermBookingsRead)
+	if !ok {
+		return
+	}
+
+	var photo models.Media
+	if err := config.DB.First(&photo, "id = ? AND booking_id = ?", c.Param("photoId"), booking.ID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Photo not found")
+		return
+	}
+	// Check-in and check-out photos are evidence; only staff remove them
+	if photo.OwnerID != userID || photo.CheckpointID != nil {
+		permissions, err := utils.CurrentPermissions(c)
+		if err != nil || !permissions.Allows(models.PermBookingsManage, map[string]string{"region": booking.Region}) {
+			if photo.CheckpointID != nil {
+				utils.ErrorResponse(c, http.StatusForbidden, "Check-in and check-out photos cannot be deleted")
+			} else {
+				utils.ErrorResponse(c, http.StatusForbidden, "Only the uploader can delete this photo")
+			}
+			return
+		}
+	}
+
+	if err := utils.ReleaseMedia(photo.ID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove image")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "booking.photo_removed", "booking", booking.ID.String(), photo, nil,
+		map[string]interface{}{"photo_id": photo.ID, "checkpoint_id": photo.CheckpointID})
+	utils.SuccessResponse(c, http.StatusOK, "Photo deleted", nil)
+}
+
+// ServeMedia streams an image from a signed URL. The signature is the only
+// access check, so links should be shared no further than the data they
+// came with.
+func ServeMedia(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Image not found")
+		return
+	}
+	variant := models.MediaVariant(c.DefaultQuery("variant", string(models.MediaOriginal)))
+	if variant != models.MediaOriginal && variant != models.MediaThumbnail {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid image variant")
+		return
+	}
+
+	expires, ok := utils.VerifyMediaURL(id, variant, c.Query("expires"), c.Query("sig"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusForbidden, "Link is invalid or has expired")
+		return
+	}
+
+	var media models.Media
+	if err := config.DB.First(&media, "id = ?", id).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	key, contentType, size := media.StorageKey, media.ContentType, media.Size
+	if variant == models.MediaThumbnail {
+		key, contentType = media.ThumbnailKey, media.ThumbnailType
+		if key != media.StorageKey {
+			size = -1
+		}
+	}
+
+	etag := fmt.Sprintf(`"%s-%s"`, media.SHA256[:16], variant)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(time.Until(expires).Seconds())))
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	blob, err := utils.Blobs.Open(key)
+	if err != nil {
+		if errors.Is(err, utils.ErrBlobNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Image not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to read image")
+		}
+		return
+	}
+	defer blob.Close()
+
+	c.DataFromReader(http.StatusOK, size, contentType, blob, nil)
+}
+
+// storeUploadedImage reads the "file" form field, stores it as media and
+// runs attach in the same transaction. It writes the error response and
+// returns false on failure.
+func storeUploadedImage(c *gin.Context, media *models.Media, attach func(tx *gorm.DB) error) bool {
+	maxBytes := int64(config.AppConfig.MaxUploadMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20) // room for the multipart framing
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponsef(c, http.StatusRequestEntityTooLarge, "File must not exceed %d MB", config.AppConfig.MaxUploadMB)
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "A file is required")
+		}
+		return false
+	}
+	if header.Size > maxBytes {
+		utils.ErrorResponsef(c, http.StatusRequestEntityTooLarge, "File must not exceed %d MB", config.AppConfig.MaxUploadMB)
+		return false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "A file is required")
+		return false
+	}
+	defer file.Close()
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := utils.StoreImage(tx, io.LimitReader(file, maxBytes), media); err != nil {
+			return err
+		}
+		if attach == nil {
+			return nil
+		}
+		return attach(tx)
+	})
+	if err != nil {
+		if err := utils.DiscardUnusedBlobs(*media); err != nil {
+			log.Printf("Failed to discard blobs of %s: %v", media.SHA256, err)
+		}
+		imageErrorResponse(c, err)
+		return false
+	}
+	return true
+}
+
+// imageErrorResponse reports an error returned while storing an image.
+func imageErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, utils.ErrUnsupportedImage):
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Images must be JPEG, PNG or GIF files")
+	case errors.Is(err, utils.ErrImageTooLarge):
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Image dimensions are too large")
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to store image")
+	}
+}
+
+func releaseReplacedMedia(id *uuid.UUID) {
+	if id == nil {
+		return
+	}
+	if err := utils.ReleaseMedia(*id); err != nil {
+		log.Printf("Failed to release media %s: %v", *id, err)
+	}
+}
+
+func findBookingForPhotos(c *gin.Context, userID uuid.UUID, perm models.Permission) (models.Booking, bool) {
+	var booking models.Booking
+	if err := config.DB.First(&booking, "id = ?", c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Booking not found")
+		return booking, false
+	}
+	if !canAccessBooking(c, userID, booking, perm) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Not authorized to view this booking")
+		return booking, false
+	}
+	return booking, true
+}
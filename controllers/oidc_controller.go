@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+var errOIDCEmailTaken = errors.New("email belongs to an existing account")
+
+// oidcLoginState is kept encrypted in a short-lived cookie between the
+// redirect to the provider and the callback.
+type oidcLoginState struct {
+	Provider     string    `json:"provider"`
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func StartOIDCLogin(c *gin.Context) {
+	provider, ok := utils.OIDCProviders[c.Param("provider")]
+	if !ok {
+		utils.ErrorResponse(c, http.StatusNotFound, "Unknown login provider")
+		return
+	}
+
+	state := oidcLoginState{Provider: provider.Name, ExpiresAt: time.Now().Add(oidcStateTTL)}
+	for _, field := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		token, err := utils.GenerateSecureToken()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+			return
+		}
+		*field = token
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC provider %s unavailable: %v", provider.Name, err)
+		utils.ErrorResponse(c, http.StatusBadGateway, "Login provider is unavailable")
+		return
+	}
+
+	raw, _ := json.Marshal(state)
+	sealed, err := utils.EncryptString(string(raw))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, sealed, int(oidcStateTTL.Seconds()), "/api/v1/auth/oidc", "", config.AppConfig.GinMode == "release", true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func OIDCCallback(c *gin.Context) {
+	provider, ok := utils.OIDCProviders[c.Param("provider")]
+	if !ok {
+		utils.ErrorResponse(c, http.StatusNotFound, "Unknown login provider")
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Login was cancelled or denied by the provider")
+		return
+	}
+
+	state, ok := readOIDCState(c)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/auth/oidc", "", config.AppConfig.GinMode == "release", true)
+	if !ok || state.Provider != provider.Name || time.Now().After(state.ExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(c.Query("state"))) != 1 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or expired login state")
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Missing authorization code")
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", provider.Name, err)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Could not verify login with provider")
+		return
+	}
+
+	user, created, err := findOrCreateOIDCUser(provider.Name, claims)
+	if errors.Is(err, errOIDCEmailTaken) {
+		utils.ErrorResponse(c, http.StatusConflict, "An account with this email already exists, log in with your password to continue")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to sign in")
+		return
+	}
+
+	if created {
+		utils.RecordAudit(c, "user.registered", "user", user.ID.String(), map[string]interface{}{"provider": provider.Name})
+	}
+
+	if !user.IsActive {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		challenge, err := utils.GenerateTwoFactorChallenge(user)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
+		return
+	}
+
+	token, err := utils.GenerateToken(user)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	status, message := http.StatusOK, "Login successful"
+	if created {
+		status, message = http.StatusCreated, "Registration successful"
+	}
+	utils.SuccessResponse(c, status, message, AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+func GetIdentities(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var identities []models.UserIdentity
+	if err := config.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch linked accounts")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Linked accounts retrieved", identities)
+}
+
+func UnlinkIdentity(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	identityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid identity ID")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	var identity models.UserIdentity
+	if err := config.DB.First(&identity, "id = ? AND user_id = ?", identityID, userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Linked account not found")
+		return
+	}
+
+	// Keep at least one way to sign in
+	var count int64
+	config.DB.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count)
+	if user.PasswordHashed == "" && count <= 1 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Set a password before unlinking your last login provider")
+		return
+	}
+
+	if err := config.DB.Delete(&identity).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to unlink account")
+		return
+	}
+
+	utils.RecordAudit(c, "identity.unlinked", "user", userID.String(), map[string]interface{}{"provider": identity.Provider})
+	utils.SuccessResponse(c, http.StatusOK, "Account unlinked", nil)
+}
+
+func readOIDCState(c *gin.Context) (oidcLoginState, bool) {
+	var state oidcLoginState
+
+	sealed, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		return state, false
+	}
+	raw, err := utils.DecryptString(sealed)
+	if err != nil {
+		return state, false
+	}
+	return state, json.Unmarshal([]byte(raw), &state) == nil
+}
+
+// findOrCreateOIDCUser resolves the local account for an external identity:
+// an existing link first, then an account with the same verified email, and
+// finally a new customer account.
+func findOrCreateOIDCUser(provider string, claims *utils.IDTokenClaims) (models.User, bool, error) {
+	var user models.User
+	created := false
+	email := strings.TrimSpace(claims.Email)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, "id = ?", identity.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if email == "" {
+			return errors.New("provider did not return an email address")
+		}
+
+		now := time.Now()
+		err = tx.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+		switch {
+		case err == nil:
+			// Only link automatically when both sides have proven the email.
+			// An unverified local account may have been registered by
+			// someone else who knows its password.
+			if !claims.EmailVerified || user.EmailVerifiedAt == nil {
+				return errOIDCEmailTaken
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			name := strings.TrimSpace(claims.Name)
+			if name == "" {
+				name = strings.Split(email, "@")[0]
+			}
+			user = models.User{
+				Email: email,
+				Name:  name,
+				Role:  models.RoleCustomer,
+			}
+			if claims.EmailVerified {
+				user.EmailVerifiedAt = &now
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			created = true
+		default:
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		}).Error
+	})
+
+	return user, created, err
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/DucLUT/goodstuff/utils/oidctest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useTestDatabase points config.DB at a transaction on the PostgreSQL
+// database in TEST_DATABASE_URL, rolled back when the test ends. Tests that
+// need it are skipped when the variable is not set.
+func useTestDatabase(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.UserIdentity{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	tx := db.Begin()
+	previous := config.DB
+	config.DB = tx
+	t.Cleanup(func() {
+		tx.Rollback()
+		config.DB = previous
+	})
+}
+
+// exchangeTestCode logs in at the fake issuer and returns the verified
+// claims, as the callback handler would.
+func exchangeTestCode(t *testing.T, subject, email string, emailVerified bool) *utils.IDTokenClaims {
+	t.Helper()
+
+	issuer := oidctest.NewIssuer(t, "client-id")
+	provider := &utils.OIDCProvider{OIDCProviderConfig: config.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      issuer.URL,
+		ClientID:    issuer.ClientID,
+		RedirectURL: "http://localhost/callback",
+	}}
+
+	claims := issuer.Claims(subject, "nonce")
+	claims["email"] = email
+	claims["email_verified"] = emailVerified
+	claims["name"] = "Test User"
+	issuer.Issue("code", issuer.Sign(t, claims))
+
+	verified, err := provider.Exchange(context.Background(), "code", "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	return verified
+}
+
+func TestFindOrCreateOIDCUserCreatesAccount(t *testing.T) {
+	useTestDatabase(t)
+	claims := exchangeTestCode(t, "new-subject", "new.user@example.com", true)
+
+	user, created, err := findOrCreateOIDCUser("test", claims)
+	if err != nil {
+		t.Fatalf("findOrCreateOIDCUser() error = %v", err)
+	}
+	if !created {
+		t.Errorf("created = false, want a new account")
+	}
+	if user.Email != "new.user@example.com" || user.Role != models.RoleCustomer || user.EmailVerifiedAt == nil {
+		t.Errorf("user = %+v, want a verified customer with the provider's email", user)
+	}
+
+	// Logging in again finds the account through the linked identity
+	again, created, err := findOrCreateOIDCUser("test", claims)
+	if err != nil {
+		t.Fatalf("second findOrCreateOIDCUser() error = %v", err)
+	}
+	if created || again.ID != user.ID {
+		t.Errorf("second login created = %v, id = %s, want the existing account %s", created, again.ID, user.ID)
+	}
+}
+
+func TestFindOrCreateOIDCUserLinksExistingAccount(t *testing.T) {
+	useTestDatabase(t)
+
+	verifiedAt := time.Now()
+	existing := models.User{Email: "existing@example.com", Name: "Existing", PasswordHashed: "hash", Role: models.RoleCustomer, EmailVerifiedAt: &verifiedAt}
+	if err := config.DB.Create(&existing).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	claims := exchangeTestCode(t, "existing-subject", "Existing@Example.com", true)
+	user, created, err := findOrCreateOIDCUser("test", claims)
+	if err != nil {
+		t.Fatalf("findOrCreateOIDCUser() error = %v", err)
+	}
+	if created || user.ID != existing.ID {
+		t.Errorf("created = %v, id = %s, want a link to %s", created, user.ID, existing.ID)
+	}
+
+	var identity models.UserIdentity
+	if err := config.DB.First(&identity, "provider = ? AND subject = ?", "test", "existing-subject").Error; err != nil {
+		t.Fatalf("identity not linked: %v", err)
+	}
+	if identity.UserID != existing.ID {
+		t.Errorf("identity.UserID = %s, want %s", identity.UserID, existing.ID)
+	}
+}
+
+func TestFindOrCreateOIDCUserRefusesUnverifiedEmail(t *testing.T) {
+	useTestDatabase(t)
+
+	existing := models.User{Email: "taken@example.com", Name: "Taken", PasswordHashed: "hash", Role: models.RoleCustomer}
+	if err := config.DB.Create(&existing).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	claims := exchangeTestCode(t, "unverified-subject", "taken@example.com", false)
+	if _, _, err := findOrCreateOIDCUser("test", claims); !errors.Is(err, errOIDCEmailTaken) {
+		t.Errorf("findOrCreateOIDCUser() error = %v, want errOIDCEmailTaken", err)
+	}
+}
+
+func TestFindOrCreateOIDCUserRefusesUnverifiedAccount(t *testing.T) {
+	useTestDatabase(t)
+
+	// Someone registered the address with a password but never verified it
+	existing := models.User{Email: "victim@example.com", Name: "Squatter", PasswordHashed: "hash", Role: models.RoleCustomer}
+	if err := config.DB.Create(&existing).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	claims := exchangeTestCode(t, "victim-subject", "victim@example.com", true)
+	if _, _, err := findOrCreateOIDCUser("test", claims); !errors.Is(err, errOIDCEmailTaken) {
+		t.Errorf("findOrCreateOIDCUser() error = %v, want errOIDCEmailTaken", err)
+	}
+
+	var linked int64
+	config.DB.Model(&models.UserIdentity{}).Where("provider = ? AND subject = ?", "test", "victim-subject").Count(&linked)
+	if linked != 0 {
+		t.Errorf("identity linked to the unverified account")
+	}
+}
@@ -0,0 +1,9 @@
+package controllers
+
+import (
+	"strings"
+)
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
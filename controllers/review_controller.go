@@ -8,6 +8,7 @@ import (
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CreateReviewInput struct {
@@ -49,31 +50,35 @@ func CreateReview(c *gin.Context) {
 		BookingID: input.BookingID,
 		Rating:    input.Rating,
 		Comment:   input.Comment,
+		Status:    models.ReviewPublished,
 	}
 
-	if err := config.DB.Create(&review).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		if booking.WorkerID == nil {
+			return nil
+		}
+		return updateWorkerRating(tx, *booking.WorkerID)
+	})
+	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create review")
 		return
 	}
 
-	// Update worker rating
-	if booking.WorkerID != nil {
-		var worker models.Worker
-		if err := config.DB.First(&worker, "id = ?", *booking.WorkerID).Error; err == nil {
-			// Calculate new average rating
-			newTotalReviews := worker.TotalReviews + 1
-			newRating := ((worker.Rating * float64(worker.TotalReviews)) + float64(input.Rating)) / float64(newTotalReviews)
-
-			config.DB.Model(&worker).Updates(map[string]interface{}{
-				"rating":        newRating,
-				"total_reviews": newTotalReviews,
-			})
-		}
-	}
+	utils.AuditChange(c, "review.created", "review", review.ID.String(), nil, review)
 
 	utils.SuccessResponse(c, http.StatusCreated, "Review submitted", review)
 }
 
+var reviewSorts = map[string]utils.SortField{
+	"created_at": {Column: "reviews.created_at", Type: utils.SortTime},
+	"rating":     {Column: "reviews.rating", Type: utils.SortNumber},
+}
+
+// GetWorkerReviews lists a worker's published reviews, newest first by
+// default.
 func GetWorkerReviews(c *gin.Context) {
 	id := c.Param("id")
 	workerID, err := uuid.Parse(id)
@@ -82,15 +87,52 @@ func GetWorkerReviews(c *gin.Context) {
 		return
 	}
 
+	page, err := utils.ParsePageQuery(c, reviewSorts, "-created_at", "reviews.id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
 	var reviews []models.Review
-	if err := config.DB.
+	query := config.DB.
 		Joins("JOIN bookings ON reviews.booking_id = bookings.id").
-		Where("bookings.worker_id = ?", workerID).
-		Preload("Booking.Customer").
-		Find(&reviews).Error; err != nil {
+		Where("bookings.worker_id = ? AND reviews.status = ?", workerID, models.ReviewPublished).
+		Preload("Booking.Customer")
+	if err := page.Apply(query).Find(&reviews).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch reviews")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Reviews retrieved", reviews)
+	reviews, meta := utils.Page(page, reviews, func(r models.Review) (interface{}, uuid.UUID) {
+		if page.SortKey == "rating" {
+			return r.Rating, r.ID
+		}
+		return r.CreatedAt, r.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Reviews retrieved", reviews, meta)
+}
+
+// updateWorkerRating recomputes a worker's rating from their published
+// reviews, so hidden reviews stop counting and restored ones count again.
+func updateWorkerRating(tx *gorm.DB, workerID uuid.UUID) error {
+	// Lock the worker so concurrent changes aggregate correctly
+	if err := tx.Exec("SELECT id FROM workers WHERE id = ? FOR UPDATE", workerID).Error; err != nil {
+		return err
+	}
+
+	var totals struct {
+		Average float64
+		Count   int
+	}
+	if err := tx.Model(&models.Review{}).
+		Select("COALESCE(AVG(reviews.rating), 0) AS average, COUNT(*) AS count").
+		Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+		Where("bookings.worker_id = ? AND reviews.status = ?", workerID, models.ReviewPublished).
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Worker{}).Where("id = ?", workerID).Updates(map[string]interface{}{
+		"rating":        totals.Average,
+		"total_reviews": totals.Count,
+	}).Error
 }
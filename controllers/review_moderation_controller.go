@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxReviewReplyLength  = 2000
+	maxReportDetailLength = 2000
+)
+
+var (
+	errAlreadyReplied  = errors.New("review already has a reply")
+	errAlreadyReported = errors.New("review already reported")
+	errReviewStatus    = errors.New("review is not in the expected status")
+	errNoOpenReports   = errors.New("review has no open reports")
+)
+
+type ReviewReplyInput struct {
+	Reply string `json:"reply" binding:"required"`
+}
+
+type ReportReviewInput struct {
+	Reason  models.ReportReason `json:"reason" binding:"required,oneof=abusive spam personal_data not_genuine other"`
+	Details string              `json:"details"`
+}
+
+// ReplyToReview posts the worker's public reply to a review of their work.
+// Each review takes one reply, which cannot be changed afterwards.
+func ReplyToReview(c *gin.Context) {
+	var input ReviewReplyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.Reply = strings.TrimSpace(input.Reply)
+	if input.Reply == "" || len([]rune(input.Reply)) > maxReviewReplyLength {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Reply must be between 1 and 2000 characters")
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var review, before models.Review
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM reviews WHERE id = ? FOR UPDATE", reviewID).Error; err != nil {
+			return err
+		}
+		if err := tx.Joins("JOIN bookings ON reviews.booking_id = bookings.id").
+			Where("bookings.worker_id = ? AND reviews.status = ?", worker.ID, models.ReviewPublished).
+			First(&review, "reviews.id = ?", reviewID).Error; err != nil {
+			return err
+		}
+		if review.RepliedAt != nil {
+			return errAlreadyReplied
+		}
+
+		before = review
+		now := time.Now()
+		review.Reply, review.RepliedAt = input.Reply, &now
+		return tx.Model(&review).Updates(map[string]interface{}{"reply": review.Reply, "replied_at": now}).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Review not found")
+		return
+	case errors.Is(err, errAlreadyReplied):
+		utils.ErrorResponse(c, http.StatusConflict, "Review already has a reply")
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save reply")
+		return
+	}
+
+	utils.AuditChange(c, "review.replied", "review", review.ID.String(), before, review)
+	utils.SuccessResponse(c, http.StatusOK, "Reply posted", review)
+}
+
+// ReportReview files a complaint about a published review for moderators.
+func ReportReview(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input ReportReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.Details = strings.TrimSpace(input.Details)
+	if len([]rune(input.Details)) > maxReportDetailLength {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Details are too long")
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var review models.Review
+	if err := config.DB.First(&review, "id = ? AND status = ?", reviewID, models.ReviewPublished).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Review not found")
+		return
+	}
+
+	report := models.ReviewReport{
+		ReviewID:   review.ID,
+		ReporterID: userID,
+		Reason:     input.Reason,
+		Details:    input.Details,
+		Status:     models.ReportOpen,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ReviewReport{}).Where("review_id = ? AND reporter_id = ?", review.ID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyReported
+		}
+		return tx.Create(&report).Error
+	})
+	// A concurrent report from the same user trips the unique index
+	if errors.Is(err, errAlreadyReported) || errors.Is(err, gorm.ErrDuplicatedKey) {
+		utils.ErrorResponse(c, http.StatusConflict, "You have already reported this review")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to report review")
+		return
+	}
+
+	utils.AuditChange(c, "review.reported", "review", review.ID.String(), nil, report)
+	utils.SuccessResponse(c, http.StatusCreated, "Review reported", report)
+}
+
+var moderationSorts = map[string]utils.SortField{
+	"created_at": {Column: "reviews.created_at", Type: utils.SortTime},
+	"rating":     {Column: "reviews.rating", Type: utils.SortNumber},
+}
+
+// AdminListReviews is the moderation queue. By default it lists reviews
+// with open reports; status=published or status=hidden lists those instead.
+func AdminListReviews(c *gin.Context) {
+	page, err := utils.ParsePageQuery(c, moderationSorts, "created_at", "reviews.id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	openReports := func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", models.ReportOpen).Order("created_at ASC")
+	}
+	query := config.DB.Preload("Booking.Customer").Preload("Booking.Worker.User").Preload("Reports", openReports)
+
+	switch c.DefaultQuery("status", "reported") {
+	case "reported":
+		query = query.Where("EXISTS (SELECT 1 FROM review_reports WHERE review_reports.review_id = reviews.id AND review_reports.status = ?)", models.ReportOpen)
+	case string(models.ReviewPublished):
+		query = query.Where("reviews.status = ?", models.ReviewPublished)
+	case string(models.ReviewHidden):
+		query = query.Where("reviews.status = ?", models.ReviewHidden)
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+
+	var reviews []models.Review
+	if err := page.Apply(query).Find(&reviews).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch reviews")
+		return
+	}
+
+	reviews, meta := utils.Page(page, reviews, func(r models.Review) (interface{}, uuid.UUID) {
+		if page.SortKey == "rating" {
+			return r.Rating, r.ID
+		}
+		return r.CreatedAt, r.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Reviews retrieved", reviews, meta)
+}
+
+// AdminGetReview shows a review with every report and moderation decision.
+func AdminGetReview(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var review models.Review
+	if err := config.DB.Preload("Booking.Customer").Preload("Booking.Worker.User").
+		Preload("Reports", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&review, "id = ?", reviewID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Review not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review retrieved", review)
+}
+
+// HideReview takes a review out of public listings and the worker's rating.
+// Its open reports are upheld.
+func HideReview(c *gin.Context) {
+	moderateReview(c, models.ModerationHidden)
+}
+
+// RestoreReview publishes a hidden review again.
+func RestoreReview(c *gin.Context) {
+	moderateReview(c, models.ModerationRestored)
+}
+
+// DismissReviewReports closes a review's open reports and keeps it
+// published.
+func DismissReviewReports(c *gin.Context) {
+	moderateReview(c, models.ModerationReportsDismissed)
+}
+
+func moderateReview(c *gin.Context, action models.ModerationAction) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input AdminReasonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	from, to := models.ReviewPublished, models.ReviewPublished
+	reportStatus := models.ReportDismissed
+	switch action {
+	case models.ModerationHidden:
+		to, reportStatus = models.ReviewHidden, models.ReportUpheld
+	case models.ModerationRestored:
+		from = models.ReviewHidden
+	}
+
+	var review, before models.Review
+	var resolved int64
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM reviews WHERE id = ? FOR UPDATE", reviewID).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Booking").First(&review, "id = ?", reviewID).Error; err != nil {
+			return err
+		}
+		if review.Status != from {
+			return errReviewStatus
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.ReviewReport{}).
+			Where("review_id = ? AND status = ?", review.ID, models.ReportOpen).
+			Updates(map[string]interface{}{"status": reportStatus, "resolved_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		resolved = result.RowsAffected
+		if action == models.ModerationReportsDismissed && resolved == 0 {
+			return errNoOpenReports
+		}
+
+		if err := tx.Create(&models.ReviewModerationEvent{
+			ReviewID: review.ID,
+			Action:   action,
+			Reason:   input.Reason,
+			ActorID:  userID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if from == to {
+			return nil
+		}
+		before = review
+		review.Status = to
+		if err := tx.Model(&review).Update("status", to).Error; err != nil {
+			return err
+		}
+		if review.Booking.WorkerID == nil {
+			return nil
+		}
+		return updateWorkerRating(tx, *review.Booking.WorkerID)
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Review not found")
+		return
+	case errors.Is(err, errReviewStatus) && action == models.ModerationRestored:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Review is not hidden")
+		return
+	case errors.Is(err, errReviewStatus):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Review is already hidden")
+		return
+	case errors.Is(err, errNoOpenReports):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Review has no open reports")
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to moderate review")
+		return
+	}
+
+	metadata := map[string]interface{}{"reason": input.Reason, "reports_resolved": resolved}
+	var message string
+	switch action {
+	case models.ModerationHidden:
+		utils.AuditChangeWithMetadata(c, "review.hidden", "review", review.ID.String(), before, review, metadata)
+		message = "Review hidden"
+	case models.ModerationRestored:
+		utils.AuditChangeWithMetadata(c, "review.restored", "review", review.ID.String(), before, review, metadata)
+		message = "Review restored"
+	default:
+		utils.RecordAudit(c, "review.reports_dismissed", "review", review.ID.String(), metadata)
+		message = "Reports dismissed"
+	}
+
+	config.DB.Preload("Reports", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&review, "id = ?", review.ID)
+	utils.SuccessResponse(c, http.StatusOK, message, review)
+}
@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreateRoleInput struct {
+	Name        string              `json:"name" binding:"required,max=64"`
+	Description string              `json:"description"`
+	Permissions []models.Permission `json:"permissions" binding:"required,min=1"`
+}
+
+type UpdateRoleInput struct {
+	Description *string             `json:"description"`
+	Permissions []models.Permission `json:"permissions"`
+}
+
+type AssignRoleInput struct {
+	RoleID uuid.UUID    `json:"role_id" binding:"required"`
+	Scope  models.Scope `json:"scope"`
+}
+
+func GetPermissions(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Permissions retrieved", models.AllPermissions)
+}
+
+func GetRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := config.DB.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch roles")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Roles retrieved", roles)
+}
+
+func CreateRole(c *gin.Context) {
+	var input CreateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	permissions, ok := rolePermissions(input.Permissions)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown permission")
+		return
+	}
+	if !canGrantPermissions(c, input.Permissions, nil) {
+		return
+	}
+
+	var existing models.Role
+	if err := config.DB.Where("name = ?", input.Name).First(&existing).Error; err == nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Role name already exists")
+		return
+	}
+
+	role := models.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: permissions,
+	}
+	if err := config.DB.Create(&role).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create role")
+		return
+	}
+
+	utils.AuditChange(c, "role.created", "role", role.ID.String(), nil, role)
+	utils.SuccessResponse(c, http.StatusCreated, "Role created", role)
+}
+
+func UpdateRole(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid role ID")
+		return
+	}
+
+	var input UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var role models.Role
+	if err := config.DB.Preload("Permissions").First(&role, "id = ?", roleID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Role not found")
+		return
+	}
+
+	permissions, ok := rolePermissions(input.Permissions)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown permission")
+		return
+	}
+	if !canGrantPermissions(c, input.Permissions, nil) {
+		return
+	}
+	before := role
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Description != nil {
+			if err := tx.Model(&role).Update("description", *input.Description).Error; err != nil {
+				return err
+			}
+		}
+		if input.Permissions != nil {
+			if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+				return err
+			}
+			for i := range permissions {
+				permissions[i].RoleID = role.ID
+			}
+			if len(permissions) > 0 {
+				if err := tx.Create(&permissions).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update role")
+		return
+	}
+
+	config.DB.Preload("Permissions").First(&role, "id = ?", role.ID)
+	utils.AuditChange(c, "role.updated", "role", role.ID.String(), before, role)
+	utils.SuccessResponse(c, http.StatusOK, "Role updated", role)
+}
+
+func DeleteRole(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid role ID")
+		return
+	}
+
+	var role models.Role
+	if err := config.DB.Preload("Permissions").First(&role, "id = ?", roleID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Role not found")
+		return
+	}
+
+	if role.IsSystem {
+		utils.ErrorResponse(c, http.StatusBadRequest, "System roles cannot be deleted")
+		return
+	}
+
+	var assigned int64
+	config.DB.Model(&models.UserRoleAssignment{}).Where("role_id = ?", role.ID).Count(&assigned)
+	if assigned > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, "Role is still assigned to users")
+		return
+	}
+
+	if err := config.DB.Delete(&role).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete role")
+		return
+	}
+
+	utils.AuditChange(c, "role.deleted", "role", role.ID.String(), role, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Role deleted", nil)
+}
+
+func GetUserRoles(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var assignments []models.UserRoleAssignment
+	if err := config.DB.Preload("Role.Permissions").Where("user_id = ?", userID).Find(&assignments).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch role assignments")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role assignments retrieved", assignments)
+}
+
+func AssignRole(c *gin.Context) {
+	actorID := c.MustGet("userID").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input AssignRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+	if !canManageUser(c, user) {
+		return
+	}
+
+	if user.Role != models.RoleStaff {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Roles can only be assigned to staff accounts")
+		return
+	}
+
+	var role models.Role
+	if err := config.DB.Preload("Permissions").First(&role, "id = ?", input.RoleID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Role not found")
+		return
+	}
+
+	// Scope values are compared with resource attributes after folding
+	// case and accents
+	scope := models.Scope{}
+	for key, value := range input.Scope {
+		normalized, err := utils.NormalizeScopeValue(value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to assign role")
+			return
+		}
+		scope[strings.ToLower(strings.TrimSpace(key))] = normalized
+	}
+
+	granted := make([]models.Permission, len(role.Permissions))
+	for i, perm := range role.Permissions {
+		granted[i] = perm.Permission
+	}
+	if !canGrantPermissions(c, granted, scope) {
+		return
+	}
+
+	assignment := models.UserRoleAssignment{
+		UserID:    user.ID,
+		RoleID:    role.ID,
+		Scope:     scope,
+		GrantedBy: &actorID,
+	}
+	if err := config.DB.Create(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to assign role")
+		return
+	}
+
+	assignment.Role = role
+	utils.AuditChange(c, "role.assigned", "user", user.ID.String(), nil, assignment)
+	utils.SuccessResponse(c, http.StatusCreated, "Role assigned", assignment)
+}
+
+func RevokeRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	assignmentID, err := uuid.Parse(c.Param("assignmentId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid assignment ID")
+		return
+	}
+
+	var assignment models.UserRoleAssignment
+	if err := config.DB.Preload("Role").First(&assignment, "id = ? AND user_id = ?", assignmentID, userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Role assignment not found")
+		return
+	}
+
+	if err := config.DB.Delete(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke role")
+		return
+	}
+
+	utils.AuditChange(c, "role.revoked", "user", userID.String(), assignment, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Role revoked", nil)
+}
+
+// canGrantPermissions reports whether the actor holds every permission in
+// scope themselves, so nobody can hand out more access than they have. A nil
+// scope, used for role definitions, only needs the permission in some scope.
+// It writes the error response when not.
+func canGrantPermissions(c *gin.Context, perms []models.Permission, scope models.Scope) bool {
+	actor, err := utils.CurrentPermissions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load permissions")
+		return false
+	}
+	for _, perm := range perms {
+		if scope == nil && actor.Has(perm) || scope != nil && actor.Allows(perm, scope) {
+			continue
+		}
+		utils.ErrorResponse(c, http.StatusForbidden, "You cannot grant permissions you do not have")
+		return false
+	}
+	return true
+}
+
+func rolePermissions(perms []models.Permission) ([]models.RolePermission, bool) {
+	seen := map[models.Permission]bool{}
+	result := make([]models.RolePermission, 0, len(perms))
+	for _, perm := range perms {
+		if !models.IsKnownPermission(perm) {
+			return nil, false
+		}
+		if seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		result = append(result, models.RolePermission{Permission: perm})
+	}
+	return result, true
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSearchQueryLength = 200
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+	defaultSuggestLimit  = 8
+	maxSuggestLimit      = 20
+
+	// Minimum pg_trgm word similarity for a name to count as a typo match
+	fuzzySimilarityThreshold = 0.4
+
+	// ts_headline wraps matches in <mark>; the rest of the text is returned
+	// as stored, so clients must escape it before rendering.
+	nameHeadlineOptions    = "HighlightAll=true, StartSel=<mark>, StopSel=</mark>"
+	snippetHeadlineOptions = "MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=\" … \", StartSel=<mark>, StopSel=</mark>"
+)
+
+type SearchResult struct {
+	Type       string     `json:"type"` // "service" or "category"
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Highlight  string     `json:"highlight"` // name with matches marked
+	Snippet    string     `json:"snippet,omitempty"`
+	CategoryID *uuid.UUID `json:"category_id,omitempty"` // parent category for both types
+	BasePrice  *float64   `json:"base_price,omitempty"`
+	Rank       float64    `json:"rank"`
+}
+
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Fuzzy   bool           `json:"fuzzy"` // no exact matches; results are closest names
+	Results []SearchResult `json:"results"`
+}
+
+type SearchSuggestion struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Search runs a ranked full-text search over visible services and
+// categories. Every term is matched as a prefix, so partially typed words
+// still match. When nothing matches it falls back to trigram similarity on
+// names to tolerate typos.
+func Search(c *gin.Context) {
+	q, kind, limit, ok := parseSearchParams(c, defaultSearchLimit, maxSearchLimit)
+	if !ok {
+		return
+	}
+
+	locale := utils.Locale(c)
+	tsquery := prefixTSQuery(q)
+	if tsquery == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search query must contain letters or digits")
+		return
+	}
+
+	var parts []string
+	var args []interface{}
+	if kind != "categories" {
+		parts = append(parts, `
+			SELECT 'service' AS type, s.id, COALESCE(t.name, s.name) AS name, s.category_id, s.base_price,
+				ts_rank_cd(s.search_vector, q.query) AS rank,
+				ts_headline('goodstuff_search', COALESCE(t.name, s.name), q.query, '`+nameHeadlineOptions+`') AS highlight,
+				ts_headline('goodstuff_search', COALESCE(NULLIF(t.description, ''), s.description, ''), q.query, '`+snippetHeadlineOptions+`') AS snippet
+			FROM services s
+			LEFT JOIN service_translations t ON t.service_id = s.id AND t.locale = ?,
+			to_tsquery('goodstuff_search', ?) AS q(query)
+			WHERE s.search_vector @@ q.query AND s.is_active AND s.deleted_at IS NULL
+				AND s.category_id IN (`+visibleCategoriesSQL+`)`)
+		args = append(args, locale, tsquery)
+	}
+	if kind != "services" {
+		parts = append(parts, `
+			SELECT 'category' AS type, sc.id, COALESCE(t.name, sc.name) AS name, sc.parent_id AS category_id, NULL::numeric AS base_price,
+				ts_rank_cd(sc.search_vector, q.query) AS rank,
+				ts_headline('goodstuff_search', COALESCE(t.name, sc.name), q.query, '`+nameHeadlineOptions+`') AS highlight,
+				ts_headline('goodstuff_search', COALESCE(NULLIF(t.description, ''), sc.description, ''), q.query, '`+snippetHeadlineOptions+`') AS snippet
+			FROM service_categories sc
+			LEFT JOIN category_translations t ON t.category_id = sc.id AND t.locale = ?,
+			to_tsquery('goodstuff_search', ?) AS q(query)
+			WHERE sc.search_vector @@ q.query AND sc.id IN (`+visibleCategoriesSQL+`)`)
+		args = append(args, locale, tsquery)
+	}
+
+	results := []SearchResult{}
+	sql := strings.Join(parts, " UNION ALL ") + " ORDER BY rank DESC, name ASC LIMIT ?"
+	if err := config.DB.Raw(sql, append(args, limit)...).Scan(&results).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Search failed")
+		return
+	}
+
+	response := SearchResponse{Query: q, Results: results}
+	if len(results) == 0 {
+		fuzzy, err := fuzzyCatalogMatches(q, locale, kind, limit)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Search failed")
+			return
+		}
+		response.Fuzzy = len(fuzzy) > 0
+		response.Results = fuzzy
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Search results", response)
+}
+
+// SearchSuggest returns names for autocomplete: names containing a word that
+// starts with the input come first, then close matches for misspellings.
+func SearchSuggest(c *gin.Context) {
+	q, kind, limit, ok := parseSearchParams(c, defaultSuggestLimit, maxSuggestLimit)
+	if !ok {
+		return
+	}
+
+	locale := utils.Locale(c)
+	pattern := escapeLike(strings.ToLower(q))
+	var parts []string
+	args := []interface{}{strings.ToLower(q), pattern}
+	if kind != "categories" {
+		parts = append(parts, `
+			SELECT 'service' AS type, s.id, COALESCE(t.name, s.name) AS name,
+				LOWER(goodstuff_unaccent(COALESCE(t.name, s.name))) AS normalized
+			FROM services s
+			LEFT JOIN service_translations t ON t.service_id = s.id AND t.locale = ?
+			WHERE s.is_active AND s.deleted_at IS NULL AND s.category_id IN (`+visibleCategoriesSQL+`)`)
+	}
+	if kind != "services" {
+		parts = append(parts, `
+			SELECT 'category' AS type, sc.id, COALESCE(t.name, sc.name) AS name,
+				LOWER(goodstuff_unaccent(COALESCE(t.name, sc.name))) AS normalized
+			FROM service_categories sc
+			LEFT JOIN category_translations t ON t.category_id = sc.id AND t.locale = ?
+			WHERE sc.id IN (`+visibleCategoriesSQL+`)`)
+	}
+
+	sql := `
+		WITH input AS (SELECT LOWER(goodstuff_unaccent(?)) AS needle, LOWER(goodstuff_unaccent(?)) AS pattern),
+		candidates AS (` + strings.Join(parts, " UNION ALL ") + `)
+		SELECT c.type, c.id, c.name
+		FROM candidates c, input i
+		WHERE c.normalized LIKE i.pattern || '%' OR c.normalized LIKE '% ' || i.pattern || '%'
+			OR i.needle <% c.normalized
+		ORDER BY (c.normalized LIKE i.pattern || '%') DESC,
+			(c.normalized LIKE '% ' || i.pattern || '%') DESC,
+			word_similarity(i.needle, c.normalized) DESC, c.name ASC
+		LIMIT ?`
+	for range parts {
+		args = append(args, locale)
+	}
+	args = append(args, limit)
+
+	suggestions := []SearchSuggestion{}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setFuzzyThreshold(tx); err != nil {
+			return err
+		}
+		return tx.Raw(sql, args...).Scan(&suggestions).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch suggestions")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Suggestions retrieved", suggestions)
+}
+
+func parseSearchParams(c *gin.Context, defaultLimit, maxLimit int) (q, kind string, limit int, ok bool) {
+	q = strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Query parameter q is required")
+		return "", "", 0, false
+	}
+	if len([]rune(q)) > maxSearchQueryLength {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search query is too long")
+		return "", "", 0, false
+	}
+
+	kind = c.DefaultQuery("type", "all")
+	if kind != "all" && kind != "services" && kind != "categories" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "type must be all, services or categories")
+		return "", "", 0, false
+	}
+
+	limit, _ = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if limit < 1 || limit > maxLimit {
+		limit = defaultLimit
+	}
+	return q, kind, limit, true
+}
+
+// prefixTSQuery turns free text into a tsquery that ANDs every word as a
+// prefix match. Only letters and digits are kept, so the result never
+// contains tsquery syntax from the user.
+func prefixTSQuery(q string) string {
+	words := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, "'"+strings.ToLower(word)+"':*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// fuzzyCatalogMatches finds names similar to q using trigram word
+// similarity, ignoring accents.
+func fuzzyCatalogMatches(q, locale, kind string, limit int) ([]SearchResult, error) {
+	var parts []string
+	args := []interface{}{q}
+	if kind != "categories" {
+		parts = append(parts, `
+			SELECT 'service' AS type, s.id, COALESCE(t.name, s.name) AS name, s.category_id, s.base_price,
+				word_similarity(i.needle, LOWER(goodstuff_unaccent(COALESCE(t.name, s.name)))) AS rank,
+				COALESCE(t.name, s.name) AS highlight
+			FROM services s
+			LEFT JOIN service_translations t ON t.service_id = s.id AND t.locale = ?,
+			input i
+			WHERE i.needle <% LOWER(goodstuff_unaccent(COALESCE(t.name, s.name))) AND s.is_active AND s.deleted_at IS NULL
+				AND s.category_id IN (`+visibleCategoriesSQL+`)`)
+	}
+	if kind != "services" {
+		parts = append(parts, `
+			SELECT 'category' AS type, sc.id, COALESCE(t.name, sc.name) AS name, sc.parent_id AS category_id, NULL::numeric AS base_price,
+				word_similarity(i.needle, LOWER(goodstuff_unaccent(COALESCE(t.name, sc.name)))) AS rank,
+				COALESCE(t.name, sc.name) AS highlight
+			FROM service_categories sc
+			LEFT JOIN category_translations t ON t.category_id = sc.id AND t.locale = ?,
+			input i
+			WHERE i.needle <% LOWER(goodstuff_unaccent(COALESCE(t.name, sc.name))) AND sc.id IN (`+visibleCategoriesSQL+`)`)
+	}
+
+	for range parts {
+		args = append(args, locale)
+	}
+	args = append(args, limit)
+
+	sql := `WITH input AS (SELECT LOWER(goodstuff_unaccent(?)) AS needle) ` +
+		strings.Join(parts, " UNION ALL ") + " ORDER BY rank DESC, name ASC LIMIT ?"
+
+	results := []SearchResult{}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setFuzzyThreshold(tx); err != nil {
+			return err
+		}
+		return tx.Raw(sql, args...).Scan(&results).Error
+	})
+	return results, err
+}
+
+// setFuzzyThreshold lowers the similarity needed by the <% operator for the
+// current transaction; the pg_trgm default only tolerates very small typos.
+func setFuzzyThreshold(tx *gorm.DB) error {
+	return tx.Exec("SET LOCAL pg_trgm.word_similarity_threshold = " + strconv.FormatFloat(fuzzySimilarityThreshold, 'f', 2, 64)).Error
+}
@@ -1,31 +1,129 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/DucLUT/goodstuff/config"
 	"github.com/DucLUT/goodstuff/models"
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+type CreateServiceInput struct {
+	CategoryID   uuid.UUID `json:"category_id" binding:"required"`
+	Name         string    `json:"name" binding:"required,max=255"`
+	Description  string    `json:"description" binding:"max=5000"`
+	BasePrice    *float64  `json:"base_price" binding:"required,min=0"`
+	PricePerHour *float64  `json:"price_per_hour" binding:"required,min=0"`
+	MinDuration  int       `json:"min_duration" binding:"required,min=15"` // minutes
+	MaxDuration  int       `json:"max_duration" binding:"required,gtefield=MinDuration,max=1440"`
+	DurationStep int       `json:"duration_step" binding:"omitempty,min=5,max=240"` // defaults to 30
+	SortOrder    int       `json:"sort_order"`
+	IsActive     *bool     `json:"is_active"` // defaults to true
+}
+
+type UpdateServiceInput struct {
+	CategoryID   *uuid.UUID `json:"category_id"`
+	Name         *string    `json:"name" binding:"omitempty,min=1,max=255"`
+	Description  *string    `json:"description" binding:"omitempty,max=5000"`
+	BasePrice    *float64   `json:"base_price" binding:"omitempty,min=0"`
+	PricePerHour *float64   `json:"price_per_hour" binding:"omitempty,min=0"`
+	MinDuration  *int       `json:"min_duration" binding:"omitempty,min=15"`
+	MaxDuration  *int       `json:"max_duration" binding:"omitempty,max=1440"`
+	DurationStep *int       `json:"duration_step" binding:"omitempty,min=5,max=240"`
+	SortOrder    *int       `json:"sort_order"`
+}
+
+type CreateCategoryInput struct {
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Name        string     `json:"name" binding:"required,max=255"`
+	Description string     `json:"description" binding:"max=5000"`
+	SortOrder   int        `json:"sort_order"`
+	IsActive    *bool      `json:"is_active"` // defaults to true
+}
+
+type UpdateCategoryInput struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=5000"`
+	SortOrder   *int    `json:"sort_order"`
+}
+
+type MoveCategoryInput struct {
+	ParentID *uuid.UUID `json:"parent_id"` // null moves the category to the top level
+}
+
+// ReorderInput lists IDs in their new display order; positions are assigned
+// from zero. Items not listed keep their current position.
+type ReorderInput struct {
+	IDs []uuid.UUID `json:"ids" binding:"required,min=1,max=500"`
+}
+
+const defaultDurationStep = 30 // minutes
+
+var errServiceHasOpenBookings = errors.New("service has open bookings")
+
+var serviceSorts = map[string]utils.SortField{
+	"sort_order": {Column: "sort_order", Type: utils.SortNumber},
+	"name":       {Column: "name", Type: utils.SortString},
+	"base_price": {Column: "base_price", Type: utils.SortNumber},
+	"created_at": {Column: "created_at", Type: utils.SortTime},
+}
+
+var categorySorts = map[string]utils.SortField{
+	"sort_order": {Column: "sort_order", Type: utils.SortNumber},
+	"name":       {Column: "name", Type: utils.SortString},
+}
+
 func GetServices(c *gin.Context) {
 	var services []models.Service
 
-	query := config.DB.Preload("Category").Where("is_active = ?", true)
+	query := config.DB.Preload("Category").
+		Where("is_active = ?", true).
+		Where("category_id IN (" + visibleCategoriesSQL + ")")
 
-	// Filter by category if provided
+	// Filter by category, including its subcategories
 	if categoryID := c.Query("category_id"); categoryID != "" {
-		query = query.Where("category_id = ?", categoryID)
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+			return
+		}
+		ids, err := categorySubtreeIDs(config.DB, id)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch services")
+			return
+		}
+		query = query.Where("category_id IN ?", ids)
 	}
 
-	if err := query.Find(&services).Error; err != nil {
+	page, err := utils.ParsePageQuery(c, serviceSorts, "sort_order", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	if err := page.Apply(query).Find(&services).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch services")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Services retrieved", services)
+	services, meta := utils.Page(page, services, func(s models.Service) (interface{}, uuid.UUID) {
+		switch page.SortKey {
+		case "name":
+			return s.Name, s.ID
+		case "base_price":
+			return s.BasePrice, s.ID
+		case "created_at":
+			return s.CreatedAt, s.ID
+		}
+		return s.SortOrder, s.ID
+	})
+	localizeServices(c, services)
+	utils.PagedResponse(c, http.StatusOK, "Services retrieved", services, meta)
 }
 
 func GetServiceByID(c *gin.Context) {
@@ -37,52 +135,661 @@ func GetServiceByID(c *gin.Context) {
 	}
 
 	var service models.Service
-	if err := config.DB.Preload("Category").First(&service, "id = ?", serviceID).Error; err != nil {
+	if err := config.DB.Preload("Category").
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, name ASC") }).
+		Preload("Options.Choices", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, label ASC") }).
+		First(&service, "id = ?", serviceID).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Service not found")
 		return
 	}
 
+	localizeService(c, &service)
 	utils.SuccessResponse(c, http.StatusOK, "Service retrieved", service)
 }
 
+// GetCategories lists visible categories flat; ?parent_id=<id> limits it to
+// the direct children of a category and ?parent_id=root to the top level.
 func GetCategories(c *gin.Context) {
 	var categories []models.ServiceCategory
 
-	if err := config.DB.Where("is_active = ?", true).Find(&categories).Error; err != nil {
+	query := config.DB.Where("id IN (" + visibleCategoriesSQL + ")")
+	switch parentID := c.Query("parent_id"); parentID {
+	case "":
+	case "root":
+		query = query.Where("parent_id IS NULL")
+	default:
+		id, err := uuid.Parse(parentID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+			return
+		}
+		query = query.Where("parent_id = ?", id)
+	}
+
+	page, err := utils.ParsePageQuery(c, categorySorts, "sort_order", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	if err := page.Apply(query).Find(&categories).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch categories")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved", categories)
+	categories, meta := utils.Page(page, categories, func(sc models.ServiceCategory) (interface{}, uuid.UUID) {
+		if page.SortKey == "name" {
+			return sc.Name, sc.ID
+		}
+		return sc.SortOrder, sc.ID
+	})
+	localizeCategories(c, categories)
+	utils.PagedResponse(c, http.StatusOK, "Categories retrieved", categories, meta)
+}
+
+// GetCategoryTree returns the visible categories nested under their parents.
+func GetCategoryTree(c *gin.Context) {
+	var categories []models.ServiceCategory
+
+	if err := config.DB.Where("id IN (" + visibleCategoriesSQL + ")").Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch categories")
+		return
+	}
+
+	localizeCategories(c, categories)
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved", buildCategoryTree(categories))
+}
+
+type CategoryDetail struct {
+	models.ServiceCategory
+	Path []models.ServiceCategory `json:"path"` // root first, for breadcrumbs
+}
+
+func GetCategoryByID(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	var category models.ServiceCategory
+	if err := config.DB.Where("id IN ("+visibleCategoriesSQL+")").First(&category, "id = ?", categoryID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	if err := config.DB.Where("parent_id = ? AND is_active = ?", category.ID, true).Order("sort_order ASC, name ASC").Find(&category.Children).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch category")
+		return
+	}
+
+	path, err := categoryAncestors(config.DB, category.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch category")
+		return
+	}
+
+	categories := []models.ServiceCategory{category}
+	localizeCategories(c, categories)
+	localizeCategories(c, path)
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved", CategoryDetail{ServiceCategory: categories[0], Path: path})
 }
 
 // Admin endpoints for managing services
+
+// AdminListServices includes inactive services, and deleted ones with
+// ?include_deleted=true.
+func AdminListServices(c *gin.Context) {
+	var services []models.Service
+
+	query := config.DB.Preload("Category")
+	if c.Query("include_deleted") == "true" {
+		query = query.Unscoped()
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+			return
+		}
+		query = query.Where("category_id = ?", id)
+	}
+
+	if err := query.Order("sort_order ASC, name ASC").Find(&services).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch services")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Services retrieved", services)
+}
+
 func CreateService(c *gin.Context) {
-	var service models.Service
-	if err := c.ShouldBindJSON(&service); err != nil {
+	var input CreateServiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := config.DB.Create(&service).Error; err != nil {
+	// Whitespace passes the required binding but would save a blank name
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+
+	if !categoryExists(input.CategoryID) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Category not found")
+		return
+	}
+
+	if input.DurationStep == 0 {
+		input.DurationStep = defaultDurationStep
+	}
+
+	service := models.Service{
+		CategoryID:   input.CategoryID,
+		Name:         name,
+		Description:  input.Description,
+		BasePrice:    *input.BasePrice,
+		PricePerHour: *input.PricePerHour,
+		MinDuration:  input.MinDuration,
+		MaxDuration:  input.MaxDuration,
+		DurationStep: input.DurationStep,
+		SortOrder:    input.SortOrder,
+		IsActive:     input.IsActive == nil || *input.IsActive,
+	}
+
+	// Select everything so an explicit is_active=false is not replaced by the column default
+	if err := config.DB.Select("*").Omit("Category", "Options").Create(&service).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create service")
 		return
 	}
 
+	utils.AuditChange(c, "service.created", "service", service.ID.String(), nil, service)
 	utils.SuccessResponse(c, http.StatusCreated, "Service created", service)
 }
 
+func UpdateService(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var input UpdateServiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before := service
+	if input.CategoryID != nil {
+		if !categoryExists(*input.CategoryID) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Category not found")
+			return
+		}
+		service.CategoryID = *input.CategoryID
+	}
+	if input.Name != nil {
+		service.Name = strings.TrimSpace(*input.Name)
+		if service.Name == "" {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Name cannot be empty")
+			return
+		}
+	}
+	if input.Description != nil {
+		service.Description = *input.Description
+	}
+	if input.BasePrice != nil {
+		service.BasePrice = *input.BasePrice
+	}
+	if input.PricePerHour != nil {
+		service.PricePerHour = *input.PricePerHour
+	}
+	if input.MinDuration != nil {
+		service.MinDuration = *input.MinDuration
+	}
+	if input.MaxDuration != nil {
+		service.MaxDuration = *input.MaxDuration
+	}
+	if input.DurationStep != nil {
+		service.DurationStep = *input.DurationStep
+	}
+	if input.SortOrder != nil {
+		service.SortOrder = *input.SortOrder
+	}
+
+	if service.MinDuration > service.MaxDuration {
+		utils.ErrorResponse(c, http.StatusBadRequest, "min_duration cannot be greater than max_duration")
+		return
+	}
+
+	if err := config.DB.Omit("Category", "Options").Save(&service).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update service")
+		return
+	}
+
+	utils.AuditChange(c, "service.updated", "service", service.ID.String(), before, service)
+	utils.SuccessResponse(c, http.StatusOK, "Service updated", service)
+}
+
+func ActivateService(c *gin.Context) {
+	setServiceActive(c, true)
+}
+
+func DeactivateService(c *gin.Context) {
+	setServiceActive(c, false)
+}
+
+func setServiceActive(c *gin.Context, active bool) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	before := service
+	if err := config.DB.Model(&service).Update("is_active", active).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update service")
+		return
+	}
+
+	action, message := "service.deactivated", "Service deactivated"
+	if active {
+		action, message = "service.activated", "Service activated"
+	}
+	utils.AuditChange(c, action, "service", service.ID.String(), before, service)
+	utils.SuccessResponse(c, http.StatusOK, message, service)
+}
+
+// DeleteService soft-deletes a service. Services with open bookings have to
+// be deactivated instead until those bookings are done.
+func DeleteService(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the service so no booking can be created for it meanwhile
+		if err := tx.Exec("SELECT id FROM services WHERE id = ? FOR UPDATE", service.ID).Error; err != nil {
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.Booking{}).
+			Where("service_id = ? AND status IN ?", service.ID, models.OpenBookingStatuses).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errServiceHasOpenBookings
+		}
+
+		return tx.Delete(&service).Error
+	})
+	if errors.Is(err, errServiceHasOpenBookings) {
+		utils.ErrorResponse(c, http.StatusConflict, "Service has open bookings, deactivate it instead")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete service")
+		return
+	}
+
+	utils.AuditChange(c, "service.deleted", "service", service.ID.String(), service, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Service deleted", nil)
+}
+
+func RestoreService(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, true)
+	if !ok {
+		return
+	}
+
+	if !service.DeletedAt.Valid {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Service is not deleted")
+		return
+	}
+
+	if !categoryExists(service.CategoryID) {
+		utils.ErrorResponse(c, http.StatusConflict, "Restore the service's category first")
+		return
+	}
+
+	if err := config.DB.Unscoped().Model(&service).Update("deleted_at", nil).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to restore service")
+		return
+	}
+	config.DB.First(&service, "id = ?", service.ID)
+
+	utils.RecordAudit(c, "service.restored", "service", service.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Service restored", service)
+}
+
+func ReorderServices(c *gin.Context) {
+	reorder(c, &models.Service{}, "service")
+}
+
+// Admin endpoints for managing categories
+
+func AdminListCategories(c *gin.Context) {
+	var categories []models.ServiceCategory
+
+	query := config.DB
+	if c.Query("include_deleted") == "true" {
+		query = query.Unscoped()
+	}
+
+	if err := query.Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch categories")
+		return
+	}
+
+	if c.Query("tree") == "true" {
+		utils.SuccessResponse(c, http.StatusOK, "Categories retrieved", buildCategoryTree(categories))
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved", categories)
+}
+
 func CreateCategory(c *gin.Context) {
-	var category models.ServiceCategory
-	if err := c.ShouldBindJSON(&category); err != nil {
+	var input CreateCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := config.DB.Create(&category).Error; err != nil {
+	if input.ParentID != nil {
+		if ok, message := validCategoryParent(*input.ParentID, nil, 1); !ok {
+			utils.ErrorResponse(c, http.StatusBadRequest, message)
+			return
+		}
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+	if categoryNameTaken(name, input.ParentID, uuid.Nil) {
+		utils.ErrorResponse(c, http.StatusConflict, "A category with this name already exists here")
+		return
+	}
+
+	category := models.ServiceCategory{
+		ParentID:    input.ParentID,
+		Name:        name,
+		Description: input.Description,
+		SortOrder:   input.SortOrder,
+		IsActive:    input.IsActive == nil || *input.IsActive,
+	}
+
+	if err := config.DB.Select("*").Omit("Services", "Children").Create(&category).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create category")
 		return
 	}
 
+	utils.AuditChange(c, "category.created", "category", category.ID.String(), nil, category)
 	utils.SuccessResponse(c, http.StatusCreated, "Category created", category)
 }
+
+func UpdateCategory(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var input UpdateCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before := category
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Name cannot be empty")
+			return
+		}
+		if categoryNameTaken(name, category.ParentID, category.ID) {
+			utils.ErrorResponse(c, http.StatusConflict, "A category with this name already exists here")
+			return
+		}
+		category.Name = name
+	}
+	if input.Description != nil {
+		category.Description = *input.Description
+	}
+	if input.SortOrder != nil {
+		category.SortOrder = *input.SortOrder
+	}
+
+	if err := config.DB.Omit("Services", "Children").Save(&category).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update category")
+		return
+	}
+
+	utils.AuditChange(c, "category.updated", "category", category.ID.String(), before, category)
+	utils.SuccessResponse(c, http.StatusOK, "Category updated", category)
+}
+
+// MoveCategory re-parents a category together with its subcategories.
+func MoveCategory(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var input MoveCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.ParentID != nil {
+		height, err := categorySubtreeHeight(config.DB, category.ID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to move category")
+			return
+		}
+		if ok, message := validCategoryParent(*input.ParentID, &category.ID, height); !ok {
+			utils.ErrorResponse(c, http.StatusBadRequest, message)
+			return
+		}
+	}
+
+	if categoryNameTaken(category.Name, input.ParentID, category.ID) {
+		utils.ErrorResponse(c, http.StatusConflict, "A category with this name already exists there")
+		return
+	}
+
+	before := category
+	if err := config.DB.Model(&category).Update("parent_id", input.ParentID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to move category")
+		return
+	}
+
+	category.ParentID = input.ParentID
+	utils.AuditChange(c, "category.moved", "category", category.ID.String(), before, category)
+	utils.SuccessResponse(c, http.StatusOK, "Category moved", category)
+}
+
+func ActivateCategory(c *gin.Context) {
+	setCategoryActive(c, true)
+}
+
+func DeactivateCategory(c *gin.Context) {
+	setCategoryActive(c, false)
+}
+
+// setCategoryActive toggles a category. Its services stay as they are but
+// are hidden from the public catalog while the category is inactive.
+func setCategoryActive(c *gin.Context, active bool) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	before := category
+	if err := config.DB.Model(&category).Update("is_active", active).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update category")
+		return
+	}
+
+	action, message := "category.deactivated", "Category deactivated"
+	if active {
+		action, message = "category.activated", "Category activated"
+	}
+	utils.AuditChange(c, action, "category", category.ID.String(), before, category)
+	utils.SuccessResponse(c, http.StatusOK, message, category)
+}
+
+func DeleteCategory(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var services, children int64
+	if err := config.DB.Model(&models.Service{}).Where("category_id = ?", category.ID).Count(&services).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete category")
+		return
+	}
+	if err := config.DB.Model(&models.ServiceCategory{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete category")
+		return
+	}
+	if services > 0 || children > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, "Category still has services or subcategories, move or delete them first")
+		return
+	}
+
+	if err := config.DB.Delete(&category).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete category")
+		return
+	}
+
+	utils.AuditChange(c, "category.deleted", "category", category.ID.String(), category, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted", nil)
+}
+
+func RestoreCategory(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, true)
+	if !ok {
+		return
+	}
+
+	if !category.DeletedAt.Valid {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Category is not deleted")
+		return
+	}
+
+	if category.ParentID != nil && !categoryExists(*category.ParentID) {
+		utils.ErrorResponse(c, http.StatusConflict, "Restore the parent category first")
+		return
+	}
+
+	if categoryNameTaken(category.Name, category.ParentID, category.ID) {
+		utils.ErrorResponse(c, http.StatusConflict, "Another category with this name now exists here")
+		return
+	}
+
+	if err := config.DB.Unscoped().Model(&category).Update("deleted_at", nil).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to restore category")
+		return
+	}
+	config.DB.First(&category, "id = ?", category.ID)
+
+	utils.RecordAudit(c, "category.restored", "category", category.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Category restored", category)
+}
+
+func ReorderCategories(c *gin.Context) {
+	reorder(c, &models.ServiceCategory{}, "category")
+}
+
+// reorder assigns sort positions in the order the IDs were given.
+func reorder(c *gin.Context, model interface{}, resourceType string) {
+	var input ReorderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range input.IDs {
+			if err := tx.Model(model).Where("id = ?", id).Update("sort_order", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to reorder")
+		return
+	}
+
+	utils.RecordAudit(c, resourceType+".reordered", resourceType, "", map[string]interface{}{"ids": input.IDs})
+	utils.SuccessResponse(c, http.StatusOK, "Order updated", nil)
+}
+
+func findServiceForAdmin(c *gin.Context, includeDeleted bool) (models.Service, bool) {
+	var service models.Service
+
+	serviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid service ID")
+		return service, false
+	}
+
+	query := config.DB
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	if err := query.First(&service, "id = ?", serviceID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Service not found")
+		return service, false
+	}
+	return service, true
+}
+
+func findCategoryForAdmin(c *gin.Context, includeDeleted bool) (models.ServiceCategory, bool) {
+	var category models.ServiceCategory
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return category, false
+	}
+
+	query := config.DB
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	if err := query.First(&category, "id = ?", categoryID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found")
+		return category, false
+	}
+	return category, true
+}
+
+func categoryExists(id uuid.UUID) bool {
+	var count int64
+	config.DB.Model(&models.ServiceCategory{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// categoryNameTaken reports whether a sibling under parentID already uses
+// the name.
+func categoryNameTaken(name string, parentID *uuid.UUID, except uuid.UUID) bool {
+	var count int64
+	query := config.DB.Model(&models.ServiceCategory{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, except)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	query.Count(&count)
+	return count > 0
+}
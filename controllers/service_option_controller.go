@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ServiceOptionInput struct {
+	Name        string                     `json:"name" binding:"required,max=128"`
+	Type        models.ServiceOptionType   `json:"type" binding:"required,oneof=single multi quantity"`
+	Required    bool                       `json:"required"`
+	MinQuantity int                        `json:"min_quantity" binding:"min=0"`
+	MaxQuantity int                        `json:"max_quantity" binding:"min=0,max=1000"`
+	UnitPrice   float64                    `json:"unit_price" binding:"min=0"`
+	SortOrder   int                        `json:"sort_order"`
+	Choices     []ServiceOptionChoiceInput `json:"choices" binding:"max=50,dive"`
+}
+
+type ServiceOptionChoiceInput struct {
+	Label      string  `json:"label" binding:"required,max=128"`
+	PriceDelta float64 `json:"price_delta"` // may be negative for discounts
+	SortOrder  int     `json:"sort_order"`
+}
+
+// BookingOptionInput selects choices for single/multi options or a quantity
+// for quantity options.
+type BookingOptionInput struct {
+	OptionID  uuid.UUID   `json:"option_id" binding:"required"`
+	ChoiceIDs []uuid.UUID `json:"choice_ids"`
+	Quantity  int         `json:"quantity" binding:"min=0"`
+}
+
+func CreateServiceOption(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var input ServiceOptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if message := validateServiceOption(input); message != "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, message)
+		return
+	}
+
+	option := models.ServiceOption{ServiceID: service.ID}
+	applyServiceOptionInput(&option, input)
+
+	if err := config.DB.Create(&option).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create option")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "service.option_created", "service", service.ID.String(), nil, option, map[string]interface{}{"choices": option.Choices})
+	utils.SuccessResponse(c, http.StatusCreated, "Option created", option)
+}
+
+// UpdateServiceOption replaces the option and its choices. Bookings keep
+// their own snapshot, so this never changes existing bookings.
+func UpdateServiceOption(c *gin.Context) {
+	option, ok := findServiceOption(c)
+	if !ok {
+		return
+	}
+
+	var input ServiceOptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if message := validateServiceOption(input); message != "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, message)
+		return
+	}
+
+	before := option
+	applyServiceOptionInput(&option, input)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("option_id = ?", option.ID).Delete(&models.ServiceOptionChoice{}).Error; err != nil {
+			return err
+		}
+		for i := range option.Choices {
+			option.Choices[i].OptionID = option.ID
+		}
+		if len(option.Choices) > 0 {
+			if err := tx.Create(&option.Choices).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Choices").Save(&option).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update option")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "service.option_updated", "service", option.ServiceID.String(), before, option, map[string]interface{}{"choices": option.Choices})
+	utils.SuccessResponse(c, http.StatusOK, "Option updated", option)
+}
+
+func DeleteServiceOption(c *gin.Context) {
+	option, ok := findServiceOption(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("option_id = ?", option.ID).Delete(&models.ServiceOptionChoice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&option).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete option")
+		return
+	}
+
+	utils.AuditChange(c, "service.option_deleted", "service", option.ServiceID.String(), option, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Option deleted", nil)
+}
+
+func findServiceOption(c *gin.Context) (models.ServiceOption, bool) {
+	var option models.ServiceOption
+
+	serviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid service ID")
+		return option, false
+	}
+	optionID, err := uuid.Parse(c.Param("optionId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid option ID")
+		return option, false
+	}
+
+	if err := config.DB.Preload("Choices").First(&option, "id = ? AND service_id = ?", optionID, serviceID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Option not found")
+		return option, false
+	}
+	return option, true
+}
+
+func validateServiceOption(input ServiceOptionInput) string {
+	switch input.Type {
+	case models.OptionSingle, models.OptionMulti:
+		if len(input.Choices) == 0 {
+			return "Select options need at least one choice"
+		}
+		seen := map[string]bool{}
+		for _, choice := range input.Choices {
+			label := strings.ToLower(strings.TrimSpace(choice.Label))
+			if seen[label] {
+				return "Choice labels must be unique"
+			}
+			seen[label] = true
+		}
+	case models.OptionQuantity:
+		if len(input.Choices) > 0 {
+			return "Quantity options cannot have choices"
+		}
+		if input.MaxQuantity < 1 || input.MaxQuantity < input.MinQuantity {
+			return "max_quantity must be at least 1 and not below min_quantity"
+		}
+	}
+	return ""
+}
+
+func applyServiceOptionInput(option *models.ServiceOption, input ServiceOptionInput) {
+	option.Name = strings.TrimSpace(input.Name)
+	option.Type = input.Type
+	option.Required = input.Required
+	option.SortOrder = input.SortOrder
+	option.MinQuantity, option.MaxQuantity, option.UnitPrice = 0, 0, 0
+	if input.Type == models.OptionQuantity {
+		option.MinQuantity = input.MinQuantity
+		option.MaxQuantity = input.MaxQuantity
+		option.UnitPrice = input.UnitPrice
+	}
+
+	option.Choices = make([]models.ServiceOptionChoice, 0, len(input.Choices))
+	for _, choice := range input.Choices {
+		option.Choices = append(option.Choices, models.ServiceOptionChoice{
+			Label:      strings.TrimSpace(choice.Label),
+			PriceDelta: choice.PriceDelta,
+			SortOrder:  choice.SortOrder,
+		})
+	}
+}
+
+// priceBookingOptions validates selections against the service's options and
+// returns the priced snapshot to store on the booking along with its total.
+// A non-nil message is a client-facing error.
+func priceBookingOptions(serviceID uuid.UUID, inputs []BookingOptionInput) (models.BookingOptions, float64, *utils.Message) {
+	var options []models.ServiceOption
+	if err := config.DB.Preload("Choices").Where("service_id = ?", serviceID).Order("sort_order ASC").Find(&options).Error; err != nil {
+		return nil, 0, utils.Messagef("Failed to load service options")
+	}
+
+	selected := map[uuid.UUID]BookingOptionInput{}
+	for _, input := range inputs {
+		if _, dup := selected[input.OptionID]; dup {
+			return nil, 0, utils.Messagef("Each option can only be selected once")
+		}
+		selected[input.OptionID] = input
+	}
+
+	snapshot := models.BookingOptions{}
+	total := 0.0
+	for _, option := range options {
+		input, ok := selected[option.ID]
+		delete(selected, option.ID)
+
+		empty := !ok || (len(input.ChoiceIDs) == 0 && input.Quantity == 0)
+		if empty {
+			if option.Required {
+				return nil, 0, utils.Messagef("Option %q is required", option.Name)
+			}
+			continue
+		}
+
+		line := models.BookingOption{OptionID: option.ID, Name: option.Name, Type: option.Type}
+		switch option.Type {
+		case models.OptionSingle, models.OptionMulti:
+			if input.Quantity != 0 {
+				return nil, 0, utils.Messagef("Option %q takes choices, not a quantity", option.Name)
+			}
+			if option.Type == models.OptionSingle && len(input.ChoiceIDs) != 1 {
+				return nil, 0, utils.Messagef("Pick exactly one choice for %q", option.Name)
+			}
+			choices := map[uuid.UUID]models.ServiceOptionChoice{}
+			for _, choice := range option.Choices {
+				choices[choice.ID] = choice
+			}
+			for _, choiceID := range input.ChoiceIDs {
+				choice, ok := choices[choiceID]
+				if !ok {
+					return nil, 0, utils.Messagef("Invalid choice for %q", option.Name)
+				}
+				delete(choices, choiceID) // also rejects duplicates
+				line.Choices = append(line.Choices, models.BookingOptionChoice{ChoiceID: choice.ID, Label: choice.Label, Price: choice.PriceDelta})
+				line.Price += choice.PriceDelta
+			}
+
+		case models.OptionQuantity:
+			if len(input.ChoiceIDs) > 0 {
+				return nil, 0, utils.Messagef("Option %q takes a quantity, not choices", option.Name)
+			}
+			if input.Quantity < option.MinQuantity || input.Quantity > option.MaxQuantity {
+				return nil, 0, utils.Messagef("Quantity for %q must be between %d and %d", option.Name, option.MinQuantity, option.MaxQuantity)
+			}
+			line.Quantity = input.Quantity
+			line.UnitPrice = option.UnitPrice
+			line.Price = float64(input.Quantity) * option.UnitPrice
+		}
+
+		line.Price = math.Round(line.Price*100) / 100
+		total += line.Price
+		snapshot = append(snapshot, line)
+	}
+
+	if len(selected) > 0 {
+		return nil, 0, utils.Messagef("Unknown option selected")
+	}
+	return snapshot, total, nil
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TranslationInput struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=5000"`
+}
+
+// Admin endpoints for catalog translations. Content in DEFAULT_LOCALE lives
+// on the service or category itself, so only other locales are stored here.
+
+func GetServiceTranslations(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var translations []models.ServiceTranslation
+	if err := config.DB.Where("service_id = ?", service.ID).Order("locale ASC").Find(&translations).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch translations")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translations retrieved", translations)
+}
+
+// PutServiceTranslation creates or replaces the translation for a locale.
+func PutServiceTranslation(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+	locale, input, ok := bindTranslation(c)
+	if !ok {
+		return
+	}
+
+	var before models.ServiceTranslation
+	existed := config.DB.Where("service_id = ? AND locale = ?", service.ID, locale).First(&before).Error == nil
+
+	translation := models.ServiceTranslation{
+		ServiceID:   service.ID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+	if existed {
+		translation.ID, translation.CreatedAt = before.ID, before.CreatedAt
+	}
+	if err := config.DB.Save(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save translation")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "service.translation_saved", "service", service.ID.String(),
+		auditBefore(existed, before), translation, map[string]interface{}{"locale": locale})
+	utils.SuccessResponse(c, http.StatusOK, "Translation saved", translation)
+}
+
+func DeleteServiceTranslation(c *gin.Context) {
+	service, ok := findServiceForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var translation models.ServiceTranslation
+	if err := config.DB.Where("service_id = ? AND locale = ?", service.ID, c.Param("locale")).First(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Translation not found")
+		return
+	}
+	if err := config.DB.Delete(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete translation")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "service.translation_deleted", "service", service.ID.String(),
+		translation, nil, map[string]interface{}{"locale": translation.Locale})
+	utils.SuccessResponse(c, http.StatusOK, "Translation deleted", nil)
+}
+
+func GetCategoryTranslations(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var translations []models.CategoryTranslation
+	if err := config.DB.Where("category_id = ?", category.ID).Order("locale ASC").Find(&translations).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch translations")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translations retrieved", translations)
+}
+
+func PutCategoryTranslation(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+	locale, input, ok := bindTranslation(c)
+	if !ok {
+		return
+	}
+
+	var before models.CategoryTranslation
+	existed := config.DB.Where("category_id = ? AND locale = ?", category.ID, locale).First(&before).Error == nil
+
+	translation := models.CategoryTranslation{
+		CategoryID:  category.ID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+	if existed {
+		translation.ID, translation.CreatedAt = before.ID, before.CreatedAt
+	}
+	if err := config.DB.Save(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save translation")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "category.translation_saved", "category", category.ID.String(),
+		auditBefore(existed, before), translation, map[string]interface{}{"locale": locale})
+	utils.SuccessResponse(c, http.StatusOK, "Translation saved", translation)
+}
+
+func DeleteCategoryTranslation(c *gin.Context) {
+	category, ok := findCategoryForAdmin(c, false)
+	if !ok {
+		return
+	}
+
+	var translation models.CategoryTranslation
+	if err := config.DB.Where("category_id = ? AND locale = ?", category.ID, c.Param("locale")).First(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Translation not found")
+		return
+	}
+	if err := config.DB.Delete(&translation).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete translation")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "category.translation_deleted", "category", category.ID.String(),
+		translation, nil, map[string]interface{}{"locale": translation.Locale})
+	utils.SuccessResponse(c, http.StatusOK, "Translation deleted", nil)
+}
+
+func bindTranslation(c *gin.Context) (string, TranslationInput, bool) {
+	var input TranslationInput
+
+	locale := strings.ToLower(c.Param("locale"))
+	if !config.IsSupportedLocale(locale) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unsupported locale")
+		return "", input, false
+	}
+	if locale == config.AppConfig.DefaultLocale {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Default locale content is edited on the item itself")
+		return "", input, false
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return "", input, false
+	}
+	return locale, input, true
+}
+
+func auditBefore(existed bool, before interface{}) interface{} {
+	if !existed {
+		return nil
+	}
+	return before
+}
+
+// localizeServices overlays the request locale's translations onto services
+// and their preloaded categories. Lookup failures leave the default locale
+// content in place.
+func localizeServices(c *gin.Context, services []models.Service) {
+	locale := utils.Locale(c)
+	if locale == config.AppConfig.DefaultLocale || len(services) == 0 {
+		return
+	}
+
+	serviceIDs := make([]uuid.UUID, 0, len(services))
+	for _, service := range services {
+		serviceIDs = append(serviceIDs, service.ID)
+	}
+
+	var translations []models.ServiceTranslation
+	config.DB.Where("service_id IN ? AND locale = ?", serviceIDs, locale).Find(&translations)
+	byService := make(map[uuid.UUID]models.ServiceTranslation, len(translations))
+	for _, translation := range translations {
+		byService[translation.ServiceID] = translation
+	}
+
+	categories := make([]models.ServiceCategory, len(services))
+	for i := range services {
+		if translation, ok := byService[services[i].ID]; ok {
+			services[i].Name = translation.Name
+			if translation.Description != "" {
+				services[i].Description = translation.Description
+			}
+		}
+		categories[i] = services[i].Category
+	}
+
+	localizeCategories(c, categories)
+	for i := range services {
+		services[i].Category = categories[i]
+	}
+}
+
+func localizeService(c *gin.Context, service *models.Service) {
+	services := []models.Service{*service}
+	localizeServices(c, services)
+	*service = services[0]
+}
+
+// localizeCategories is the category counterpart of localizeServices and
+// also covers nested children.
+func localizeCategories(c *gin.Context, categories []models.ServiceCategory) {
+	locale := utils.Locale(c)
+	if locale == config.AppConfig.DefaultLocale || len(categories) == 0 {
+		return
+	}
+
+	var ids []uuid.UUID
+	var collect func(nodes []models.ServiceCategory)
+	collect = func(nodes []models.ServiceCategory) {
+		for _, node := range nodes {
+			if node.ID != uuid.Nil {
+				ids = append(ids, node.ID)
+			}
+			collect(node.Children)
+		}
+	}
+	collect(categories)
+	if len(ids) == 0 {
+		return
+	}
+
+	var translations []models.CategoryTranslation
+	config.DB.Where("category_id IN ? AND locale = ?", ids, locale).Find(&translations)
+	byCategory := make(map[uuid.UUID]models.CategoryTranslation, len(translations))
+	for _, translation := range translations {
+		byCategory[translation.CategoryID] = translation
+	}
+
+	var apply func(nodes []models.ServiceCategory)
+	apply = func(nodes []models.ServiceCategory) {
+		for i := range nodes {
+			if translation, ok := byCategory[nodes[i].ID]; ok {
+				nodes[i].Name = translation.Name
+				if translation.Description != "" {
+					nodes[i].Description = translation.Description
+				}
+			}
+			apply(nodes[i].Children)
+		}
+	}
+	apply(categories)
+}
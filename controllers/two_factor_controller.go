@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func EnrollTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate secret")
+		return
+	}
+
+	encrypted, err := utils.EncryptString(secret)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate secret")
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"two_factor_secret":    encrypted,
+		"two_factor_last_step": 0,
+	}).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start enrollment")
+		return
+	}
+
+	utils.RecordAudit(c, "2fa.enrollment_started", "user", user.ID.String(), nil)
+
+	utils.SuccessResponse(c, http.StatusOK, "Scan the code with your authenticator app, then confirm with a code", TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPProvisioningURI(secret, user.Email),
+	})
+}
+
+func ConfirmTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	}
+	if user.TwoFactorSecret == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start enrollment first")
+		return
+	}
+
+	if !checkSecondFactor(c, &user, input.Code, verifyTOTP, http.StatusBadRequest) {
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(user.ID, func(tx *gorm.DB) error {
+		return tx.Model(&user).Update("two_factor_enabled", true).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to enable two-factor authentication")
+		return
+	}
+
+	utils.RecordAudit(c, "2fa.enabled", "user", user.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled. Store these recovery codes somewhere safe", RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func DisableTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input DisableTwoFactorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.Role == models.RoleAdmin {
+		utils.ErrorResponse(c, http.StatusForbidden, "Two-factor authentication is mandatory for admins")
+		return
+	}
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+
+	if !utils.CheckPassword(input.Password, user.PasswordHashed) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Current password is incorrect")
+		return
+	}
+
+	if !checkSecondFactor(c, &user, input.Code, verifySecondFactor, http.StatusBadRequest) {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":   false,
+			"two_factor_secret":    "",
+			"two_factor_last_step": 0,
+		}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+		return
+	}
+
+	utils.RecordAudit(c, "2fa.disabled", "user", user.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+
+	if !checkSecondFactor(c, &user, input.Code, verifyTOTP, http.StatusBadRequest) {
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(user.ID, nil)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate recovery codes")
+		return
+	}
+
+	utils.RecordAudit(c, "2fa.recovery_codes_regenerated", "user", user.ID.String(), nil)
+	utils.SuccessResponse(c, http.StatusOK, "Recovery codes regenerated", RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// VerifyTwoFactorLogin exchanges a challenge token from Login plus a TOTP or
+// recovery code for a full session.
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var input TwoFactorLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	claims, err := utils.ValidateToken(input.ChallengeToken)
+	if err != nil || claims.TokenUse != utils.TokenUseTwoFactorChallenge {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired challenge")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", claims.UserID).Error; err != nil ||
+		!user.IsActive || !user.TwoFactorEnabled || user.SessionVersion != claims.SessionVersion {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired challenge")
+		return
+	}
+
+	if !checkSecondFactor(c, &user, input.Code, verifySecondFactor, http.StatusUnauthorized) {
+		return
+	}
+
+	token, err := utils.GenerateToken(user)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// checkSecondFactor verifies a code under the same per-account throttle as
+// logins, so no endpoint can be used to guess codes faster. It writes the
+// error response, using failStatus for a wrong code, and returns false on
+// failure.
+func checkSecondFactor(c *gin.Context, user *models.User, code string, verify func(*models.User, string) (bool, error), failStatus int) bool {
+	now := time.Now()
+	throttleKey := "2fa:" + user.ID.String()
+	wait, err := utils.AccountLoginThrottle.Check(throttleKey, now)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify code")
+		return false
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many attempts, please try again later")
+		return false
+	}
+	attempt, allowed, err := utils.AccountLoginThrottle.Reserve(throttleKey, now)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify code")
+		return false
+	}
+	if !allowed {
+		utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many attempts, please try again later")
+		return false
+	}
+
+	ok, err := verify(user, code)
+	if err != nil {
+		utils.AccountLoginThrottle.Release(throttleKey)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify code")
+		return false
+	}
+	if !ok {
+		if locked, err := utils.AccountLoginThrottle.Fail(throttleKey, attempt, now); err == nil && locked {
+			utils.RecordAudit(c, "auth.lockout", "login", throttleKey, map[string]interface{}{
+				"scope":        "2fa",
+				"locked_until": now.Add(utils.AccountLoginThrottle.LockoutDuration),
+			})
+		}
+		utils.ErrorResponse(c, failStatus, "Invalid verification code")
+		return false
+	}
+	utils.AccountLoginThrottle.Succeed(throttleKey)
+	return true
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused
+// recovery code.
+func verifySecondFactor(user *models.User, code string) (bool, error) {
+	ok, err := verifyTOTP(user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+	return useRecoveryCode(user.ID, code)
+}
+
+func verifyTOTP(user *models.User, code string) (bool, error) {
+	secret, err := utils.DecryptString(user.TwoFactorSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now(), user.TwoFactorLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	// Record the step atomically so the same code cannot be used twice
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	user.TwoFactorLastStep = step
+	return result.RowsAffected == 1, nil
+}
+
+func useRecoveryCode(userID uuid.UUID, code string) (bool, error) {
+	normalized := utils.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// replaceRecoveryCodes swaps the user's recovery codes for a fresh set inside
+// a transaction, optionally running extra work in the same transaction.
+func replaceRecoveryCodes(userID uuid.UUID, extra func(tx *gorm.DB) error) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if extra != nil {
+			if err := extra(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		rows := make([]models.RecoveryCode, len(codes))
+		for i, code := range codes {
+			rows[i] = models.RecoveryCode{
+				UserID:   userID,
+				CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+			}
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -14,7 +14,6 @@ type UpdateProfileInput struct {
 	Name    string `json:"name"`
 	Phone   string `json:"phone"`
 	Address string `json:"address"`
-	Avatar  string `json:"avatar"`
 }
 
 type ChangePasswordInput struct {
@@ -53,22 +52,23 @@ func UpdateProfile(c *gin.Context) {
 	if input.Name != "" {
 		updates["name"] = input.Name
 	}
-	if input.Phone != "" {
+	if input.Phone != "" && input.Phone != user.Phone {
+		// A new number has to be verified again
 		updates["phone"] = input.Phone
+		updates["phone_verified_at"] = nil
 	}
 	if input.Address != "" {
 		updates["address"] = input.Address
 	}
-	if input.Avatar != "" {
-		updates["avatar"] = input.Avatar
-	}
 
+	before := user
 	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update profile")
 		return
 	}
 
 	config.DB.First(&user, "id = ?", userID)
+	utils.AuditChange(c, "user.profile_updated", "user", user.ID.String(), before, user)
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated", user)
 }
 
@@ -103,5 +103,6 @@ func ChangePassword(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "user.password_changed", "user", user.ID.String(), nil)
 	utils.SuccessResponse(c, http.StatusOK, "Password changed successfully", nil)
 }
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	phoneCodeTTL         = 10 * time.Minute
+	phoneCodeDigits      = 6
+	maxCodeAttempts      = 5
+	verificationCooldown = time.Minute
+	maxSendsPerHour      = 5
+)
+
+var errTooManyVerificationRequests = errors.New("too many verification requests, please try again later")
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type VerifyPhoneInput struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// issueVerificationToken supersedes outstanding tokens for the same purpose
+// and stores the hash of a new one, enforcing resend limits.
+func issueVerificationToken(user models.User, purpose models.VerificationPurpose, target, token string, ttl time.Duration) error {
+	now := time.Now()
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var recent []models.VerificationToken
+		if err := tx.Where("user_id = ? AND purpose = ? AND created_at > ?", user.ID, purpose, now.Add(-time.Hour)).
+			Order("created_at DESC").
+			Find(&recent).Error; err != nil {
+			return err
+		}
+
+		if len(recent) >= maxSendsPerHour || (len(recent) > 0 && now.Sub(recent[0].CreatedAt) < verificationCooldown) {
+			return errTooManyVerificationRequests
+		}
+
+		if err := tx.Model(&models.VerificationToken{}).
+			Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", user.ID, purpose).
+			Update("consumed_at", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.VerificationToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			Target:    target,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+func sendEmailVerification(user models.User) error {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	if err := issueVerificationToken(user, models.PurposeEmailVerification, user.Email, token, emailVerificationTTL); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", config.AppConfig.AppBaseURL, token)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in 24 hours.", user.Name, link)
+	return utils.Mailer.SendEmail(user.Email, "Confirm your email address", body)
+}
+
+func sendPhoneVerification(user models.User) error {
+	code, err := utils.GenerateNumericCode(phoneCodeDigits)
+	if err != nil {
+		return err
+	}
+
+	if err := issueVerificationToken(user, models.PurposePhoneVerification, user.Phone, code, phoneCodeTTL); err != nil {
+		return err
+	}
+
+	return utils.SMS.SendSMS(user.Phone, fmt.Sprintf("Your goodstuff verification code is %s. It expires in 10 minutes.", code))
+}
+
+func sendVerificationError(c *gin.Context, err error) {
+	if errors.Is(err, errTooManyVerificationRequests) {
+		utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many verification requests, please try again later")
+		return
+	}
+	utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send verification")
+}
+
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var input VerifyEmailInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		token = input.Token
+	}
+
+	var verification models.VerificationToken
+	if err := config.DB.Where("token_hash = ? AND purpose = ? AND consumed_at IS NULL", utils.HashToken(token), models.PurposeEmailVerification).
+		First(&verification).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or already used verification link")
+		return
+	}
+
+	if time.Now().After(verification.ExpiresAt) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Verification link has expired")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", verification.UserID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	// The address may have changed since the link was sent
+	if user.Email != verification.Target {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or already used verification link")
+		return
+	}
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&verification).Update("consumed_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	utils.RecordAudit(c, "user.email_verified", "user", user.ID.String(), map[string]interface{}{"email": user.Email})
+
+	utils.SuccessResponse(c, http.StatusOK, "Email verified", nil)
+}
+
+func ResendEmailVerification(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Email is already verified")
+		return
+	}
+
+	if err := sendEmailVerification(user); err != nil {
+		sendVerificationError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification email sent", nil)
+}
+
+func SendPhoneVerification(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.Phone == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Add a phone number to your profile first")
+		return
+	}
+	if user.PhoneVerifiedAt != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Phone number is already verified")
+		return
+	}
+
+	if err := sendPhoneVerification(user); err != nil {
+		sendVerificationError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification code sent", nil)
+}
+
+func VerifyPhone(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var input VerifyPhoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	var verification models.VerificationToken
+	if err := config.DB.Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", userID, models.PurposePhoneVerification).
+		Order("created_at DESC").
+		First(&verification).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "No verification code pending, please request a new one")
+		return
+	}
+
+	if time.Now().After(verification.ExpiresAt) || verification.Target != user.Phone {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Verification code has expired, please request a new one")
+		return
+	}
+
+	// Count the attempt up front so concurrent guesses cannot exceed the limit
+	result := config.DB.Model(&models.VerificationToken{}).
+		Where("id = ? AND attempts < ?", verification.ID, maxCodeAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify phone number")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many attempts, please request a new code")
+		return
+	}
+
+	if !utils.TokenHashMatches(input.Code, verification.TokenHash) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid verification code")
+		return
+	}
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&verification).Update("consumed_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("phone_verified_at", now).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify phone number")
+		return
+	}
+
+	utils.RecordAudit(c, "user.phone_verified", "user", user.ID.String(), map[string]interface{}{"phone": user.Phone})
+	utils.SuccessResponse(c, http.StatusOK, "Phone number verified", nil)
+}
@@ -1,13 +1,20 @@
 package controllers
 
 import (
+	"fmt"
+	"maps"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/DucLUT/goodstuff/config"
 	"github.com/DucLUT/goodstuff/models"
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type UpdateWorkerInput struct {
@@ -16,24 +23,219 @@ type UpdateWorkerInput struct {
 	ServiceAreas string  `json:"service_areas"`
 	WorkingHours string  `json:"working_hours"`
 	IsAvailable  *bool   `json:"is_available"`
+	// Base location for distance searches; latitude and longitude are set together
+	Latitude        *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude       *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	ServiceRadiusKm *float64 `json:"service_radius_km" binding:"omitempty,gt=0,max=50"`
 }
 
+var workerSorts = map[string]utils.SortField{
+	"rating":      {Column: "workers.rating", Type: utils.SortNumber},
+	"total_jobs":  {Column: "workers.total_jobs", Type: utils.SortNumber},
+	"hourly_rate": {Column: "workers.hourly_rate", Type: utils.SortNumber},
+	"created_at":  {Column: "workers.created_at", Type: utils.SortTime},
+}
+
+// GetWorkers searches available workers, best rated first by default.
+// Filters: verified=true, service_id, min_rating, max_rate, language,
+// lat/lng with optional max_distance_km (workers must also cover the point
+// within their own service radius), and available_at (RFC 3339) with
+// optional duration_minutes. Sort by rating, total_jobs, hourly_rate,
+// created_at or, with lat/lng, distance.
 func GetWorkers(c *gin.Context) {
 	var workers []models.Worker
 
-	query := config.DB.Preload("User").Where("is_available = ?", true)
+	query := config.DB.Model(&models.Worker{}).Preload("User").Where("workers.is_available = ?", true)
 
 	// Filter by verified status
 	if verified := c.Query("verified"); verified == "true" {
-		query = query.Where("is_verified = ?", true)
+		query = query.Where("workers.is_verified = ?", true)
+	}
+
+	if serviceID := c.Query("service_id"); serviceID != "" {
+		id, err := uuid.Parse(serviceID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid service ID")
+			return
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM worker_services ws WHERE ws.worker_id = workers.id AND ws.service_id = ?)", id)
+	}
+
+	if minRating := c.Query("min_rating"); minRating != "" {
+		rating, err := strconv.ParseFloat(minRating, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "min_rating must be between 0 and 5")
+			return
+		}
+		query = query.Where("workers.rating >= ?", rating)
+	}
+
+	if maxRate := c.Query("max_rate"); maxRate != "" {
+		rate, err := strconv.ParseFloat(maxRate, 64)
+		if err != nil || rate < 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "max_rate must be a positive number")
+			return
+		}
+		query = query.Where("workers.hourly_rate <= ?", rate)
+	}
+
+	if language := strings.ToLower(c.Query("language")); language != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM worker_languages wl WHERE wl.worker_id = workers.id AND wl.language = ?)", language)
+	}
+
+	query, ok := applyWorkerAvailabilityFilter(c, query)
+	if !ok {
+		return
+	}
+
+	sorts := workerSorts
+	query, distance, ok := applyWorkerDistanceFilter(c, query)
+	if !ok {
+		return
+	}
+	if distance != "" {
+		sorts = maps.Clone(workerSorts)
+		sorts["distance"] = utils.SortField{Column: distance, Type: utils.SortNumber}
+	} else if c.Query("sort") == "distance" || c.Query("sort") == "-distance" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Sorting by distance requires lat and lng")
+		return
+	}
+
+	page, err := utils.ParsePageQuery(c, sorts, "-rating", "workers.id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
 	}
 
-	if err := query.Find(&workers).Error; err != nil {
+	if err := page.Apply(query).Find(&workers).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch workers")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Workers retrieved", workers)
+	workers, meta := utils.Page(page, workers, func(w models.Worker) (interface{}, uuid.UUID) {
+		switch page.SortKey {
+		case "total_jobs":
+			return w.TotalJobs, w.ID
+		case "hourly_rate":
+			return w.HourlyRate, w.ID
+		case "created_at":
+			return w.CreatedAt, w.ID
+		case "distance":
+			return *w.DistanceKm, w.ID
+		}
+		return w.Rating, w.ID
+	})
+	// The exact distance would let searches from a few points locate a
+	// worker's base; the cursor above keeps the exact value
+	for i := range workers {
+		if d := workers[i].DistanceKm; d != nil {
+			rounded := math.Round(*d*10) / 10
+			workers[i].DistanceKm = &rounded
+		}
+	}
+	utils.PagedResponse(c, http.StatusOK, "Workers retrieved", workers, meta)
+}
+
+// applyWorkerDistanceFilter limits the search to workers who cover lat/lng
+// and selects their distance. A bounding box on the indexed coordinates
+// narrows the candidates before the exact great-circle distance is checked.
+// It returns the distance expression, or "" when no location was given.
+func applyWorkerDistanceFilter(c *gin.Context, query *gorm.DB) (*gorm.DB, string, bool) {
+	rawLat, rawLng := c.Query("lat"), c.Query("lng")
+	if rawLat == "" && rawLng == "" {
+		return query, "", true
+	}
+
+	lat, latErr := strconv.ParseFloat(rawLat, 64)
+	lng, lngErr := strconv.ParseFloat(rawLng, 64)
+	if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "lat and lng must be valid coordinates")
+		return nil, "", false
+	}
+
+	maxDistance := float64(models.MaxServiceRadiusKm)
+	if raw := c.Query("max_distance_km"); raw != "" {
+		d, err := strconv.ParseFloat(raw, 64)
+		if err != nil || d <= 0 || d > models.MaxServiceRadiusKm {
+			utils.ErrorResponsef(c, http.StatusBadRequest, "max_distance_km must be between 0 and %d", models.MaxServiceRadiusKm)
+			return nil, "", false
+		}
+		maxDistance = d
+	}
+
+	// The coordinates are parsed floats, so formatting them into the SQL is
+	// safe; it lets the same expression serve as a sort column.
+	distance := haversineKmSQL("workers.latitude", "workers.longitude", lat, lng)
+
+	latDelta := maxDistance / kmPerDegree
+	lngDelta := maxDistance / (kmPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+	query = query.
+		Select("workers.*, "+distance+" AS distance_km").
+		Where("workers.latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta).
+		Where("workers.longitude BETWEEN ? AND ?", lng-lngDelta, lng+lngDelta).
+		Where(distance+" <= LEAST(workers.service_radius_km, ?)", maxDistance)
+	return query, distance, true
+}
+
+// applyWorkerAvailabilityFilter keeps workers whose weekly schedule covers
+// available_at for duration_minutes and who have no overlapping open booking.
+func applyWorkerAvailabilityFilter(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	raw := c.Query("available_at")
+	if raw == "" {
+		return query, true
+	}
+
+	start, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid available_at time, use RFC 3339")
+		return nil, false
+	}
+
+	duration := defaultAvailabilityMinutes
+	if rawDuration := c.Query("duration_minutes"); rawDuration != "" {
+		duration, err = strconv.Atoi(rawDuration)
+		if err != nil || duration < 1 || duration > 24*60 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "duration_minutes must be between 1 and 1440")
+			return nil, false
+		}
+	}
+	end := start.Add(time.Duration(duration) * time.Minute)
+
+	local := start.In(config.AppConfig.Location)
+	startMinute := local.Hour()*60 + local.Minute()
+
+	query = query.
+		Where(`EXISTS (SELECT 1 FROM worker_availability_slots s
+			WHERE s.worker_id = workers.id AND s.weekday = ? AND s.start_minute <= ? AND s.end_minute >= ?)`,
+			int(local.Weekday()), startMinute, startMinute+duration).
+		Where(`NOT EXISTS (SELECT 1 FROM bookings b
+			WHERE b.worker_id = workers.id AND b.status IN ? AND b.deleted_at IS NULL
+				AND b.scheduled_at < ? AND b.scheduled_at + b.duration_minutes * INTERVAL '1 minute' > ?)`,
+			models.OpenBookingStatuses, end, start)
+	return query, true
+}
+
+// preloadWorkerDetails loads what a worker's profile page shows.
+func preloadWorkerDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").
+		Preload("Services", "is_active = ?", true).
+		Preload("Languages").
+		Preload("AvailabilitySlots", func(db *gorm.DB) *gorm.DB { return db.Order("weekday ASC, start_minute ASC") })
+}
+
+const (
+	defaultAvailabilityMinutes = 60
+	kmPerDegree                = 111.045
+)
+
+// haversineKmSQL returns an SQL expression for the great-circle distance in
+// kilometres between the given columns and a fixed point.
+func haversineKmSQL(latColumn, lngColumn string, lat, lng float64) string {
+	latSQL := strconv.FormatFloat(lat, 'f', -1, 64)
+	lngSQL := strconv.FormatFloat(lng, 'f', -1, 64)
+	return fmt.Sprintf("(12742 * ASIN(SQRT(POWER(SIN(RADIANS(%[1]s - %[3]s) / 2), 2) + "+
+		"COS(RADIANS(%[3]s)) * COS(RADIANS(%[1]s)) * POWER(SIN(RADIANS(%[2]s - %[4]s) / 2), 2))))",
+		latColumn, lngColumn, latSQL, lngSQL)
 }
 
 func GetWorkerByID(c *gin.Context) {
@@ -45,7 +247,7 @@ func GetWorkerByID(c *gin.Context) {
 	}
 
 	var worker models.Worker
-	if err := config.DB.Preload("User").First(&worker, "id = ?", workerID).Error; err != nil {
+	if err := preloadWorkerDetails(config.DB).First(&worker, "id = ?", workerID).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Worker not found")
 		return
 	}
@@ -53,16 +255,28 @@ func GetWorkerByID(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Worker retrieved", worker)
 }
 
+// WorkerProfile is the worker's own profile, including the base location
+// that is hidden from everyone else.
+type WorkerProfile struct {
+	models.Worker
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+func workerProfile(worker models.Worker) WorkerProfile {
+	return WorkerProfile{Worker: worker, Latitude: worker.Latitude, Longitude: worker.Longitude}
+}
+
 func GetWorkerProfile(c *gin.Context) {
 	userID := c.MustGet("userID").(uuid.UUID)
 
 	var worker models.Worker
-	if err := config.DB.Preload("User").First(&worker, "user_id = ?", userID).Error; err != nil {
+	if err := preloadWorkerDetails(config.DB).First(&worker, "user_id = ?", userID).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Worker profile not found")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Worker profile retrieved", worker)
+	utils.SuccessResponse(c, http.StatusOK, "Worker profile retrieved", workerProfile(worker))
 }
 
 func UpdateWorkerProfile(c *gin.Context) {
@@ -96,14 +310,23 @@ func UpdateWorkerProfile(c *gin.Context) {
 	if input.IsAvailable != nil {
 		updates["is_available"] = *input.IsAvailable
 	}
+	if input.Latitude != nil {
+		updates["latitude"] = *input.Latitude
+		updates["longitude"] = *input.Longitude
+	}
+	if input.ServiceRadiusKm != nil {
+		updates["service_radius_km"] = *input.ServiceRadiusKm
+	}
 
+	before := worker
 	if err := config.DB.Model(&worker).Updates(updates).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update worker profile")
 		return
 	}
 
 	config.DB.Preload("User").First(&worker, "user_id = ?", userID)
-	utils.SuccessResponse(c, http.StatusOK, "Worker profile updated", worker)
+	utils.AuditChange(c, "worker.profile_updated", "worker", worker.ID.String(), before, worker)
+	utils.SuccessResponse(c, http.StatusOK, "Worker profile updated", workerProfile(worker))
 }
 
 func SetAvailability(c *gin.Context) {
@@ -123,10 +346,13 @@ func SetAvailability(c *gin.Context) {
 		return
 	}
 
+	before := worker
 	if err := config.DB.Model(&worker).Update("is_available", input.IsAvailable).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update availability")
 		return
 	}
 
+	utils.AuditChange(c, "worker.availability_updated", "worker", worker.ID.String(), before, worker)
+
 	utils.SuccessResponse(c, http.StatusOK, "Availability updated", map[string]bool{"is_available": input.IsAvailable})
 }
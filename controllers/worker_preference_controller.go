@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxWorkerPreferences = 200
+
+// Customers keep one list of favorite workers and one of blocked workers.
+// A worker is on at most one of them: adding to either list moves them.
+
+func GetFavoriteWorkers(c *gin.Context) {
+	listWorkerPreferences(c, models.PreferenceFavorite)
+}
+
+func AddFavoriteWorker(c *gin.Context) {
+	setWorkerPreference(c, models.PreferenceFavorite)
+}
+
+func RemoveFavoriteWorker(c *gin.Context) {
+	removeWorkerPreference(c, models.PreferenceFavorite)
+}
+
+func GetBlockedWorkers(c *gin.Context) {
+	listWorkerPreferences(c, models.PreferenceBlocked)
+}
+
+func BlockWorker(c *gin.Context) {
+	setWorkerPreference(c, models.PreferenceBlocked)
+}
+
+func UnblockWorker(c *gin.Context) {
+	removeWorkerPreference(c, models.PreferenceBlocked)
+}
+
+func listWorkerPreferences(c *gin.Context, kind models.WorkerPreferenceKind) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var preferences []models.WorkerPreference
+	if err := config.DB.Preload("Worker.User").
+		Where("customer_id = ? AND kind = ?", userID, kind).
+		Order("created_at DESC").Find(&preferences).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch workers")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workers retrieved", preferences)
+}
+
+func setWorkerPreference(c *gin.Context, kind models.WorkerPreferenceKind) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	workerID, err := uuid.Parse(c.Param("workerId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid worker ID")
+		return
+	}
+
+	// Deactivated and deleted accounts cannot be added to either list
+	var worker models.Worker
+	if err := config.DB.Joins("JOIN users ON users.id = workers.user_id AND users.deleted_at IS NULL").
+		Where("users.is_active = ?", true).
+		First(&worker, "workers.id = ?", workerID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Worker not found")
+		return
+	}
+	if worker.UserID == userID {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot add yourself")
+		return
+	}
+
+	var before models.WorkerPreference
+	existed := config.DB.Where("customer_id = ? AND worker_id = ?", userID, workerID).First(&before).Error == nil
+	if existed && before.Kind == kind {
+		utils.SuccessResponse(c, http.StatusOK, "Worker saved", before)
+		return
+	}
+	if !existed {
+		var count int64
+		config.DB.Model(&models.WorkerPreference{}).Where("customer_id = ?", userID).Count(&count)
+		if count >= maxWorkerPreferences {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Worker list is full")
+			return
+		}
+	}
+
+	preference := models.WorkerPreference{CustomerID: userID, WorkerID: workerID, Kind: kind}
+	if existed {
+		preference.CreatedAt = before.CreatedAt
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&preference).Error; err != nil {
+			return err
+		}
+		if kind != models.PreferenceBlocked {
+			return nil
+		}
+		// Release any pending booking this customer reserved for the worker
+		return tx.Model(&models.Booking{}).
+			Where("customer_id = ? AND preferred_worker_id = ? AND status = ? AND worker_id IS NULL",
+				userID, workerID, models.StatusPending).
+			Update("preferred_until", time.Now()).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save worker")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "worker_preference.saved", "worker", workerID.String(),
+		auditBefore(existed, before), preference, map[string]interface{}{"kind": kind})
+	utils.SuccessResponse(c, http.StatusOK, "Worker saved", preference)
+}
+
+func removeWorkerPreference(c *gin.Context, kind models.WorkerPreferenceKind) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	workerID, err := uuid.Parse(c.Param("workerId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid worker ID")
+		return
+	}
+
+	var preference models.WorkerPreference
+	if err := config.DB.Where("customer_id = ? AND worker_id = ? AND kind = ?", userID, workerID, kind).
+		First(&preference).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Worker not found")
+		return
+	}
+	if err := config.DB.Delete(&preference).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove worker")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "worker_preference.removed", "worker", preference.WorkerID.String(),
+		preference, nil, map[string]interface{}{"kind": kind})
+	utils.SuccessResponse(c, http.StatusOK, "Worker removed", nil)
+}
+
+// customerBlockedWorker reports whether customerID has blocked workerID.
+func customerBlockedWorker(tx *gorm.DB, customerID, workerID uuid.UUID) (bool, error) {
+	var count int64
+	err := tx.Model(&models.WorkerPreference{}).
+		Where("customer_id = ? AND worker_id = ? AND kind = ?", customerID, workerID, models.PreferenceBlocked).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// openToWorker limits a bookings query to jobs the worker may see: the
+// customer has not blocked them, and no other worker holds a live
+// reservation on the booking.
+func openToWorker(query *gorm.DB, workerID uuid.UUID, now time.Time) *gorm.DB {
+	return query.
+		Where("NOT EXISTS (SELECT 1 FROM worker_preferences wp WHERE wp.customer_id = bookings.customer_id AND wp.worker_id = ? AND wp.kind = ?)",
+			workerID, models.PreferenceBlocked).
+		Where("(bookings.preferred_worker_id IS NULL OR bookings.preferred_worker_id = ? OR bookings.preferred_until <= ?)",
+			workerID, now)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SetWorkerServicesInput struct {
+	ServiceIDs []uuid.UUID `json:"service_ids" binding:"max=100"`
+}
+
+type SetWorkerLanguagesInput struct {
+	Languages []string `json:"languages" binding:"max=20,dive,min=2,max=3,alpha"` // ISO 639-1 codes such as "vi"
+}
+
+type AvailabilitySlotInput struct {
+	Weekday int    `json:"weekday" binding:"min=0,max=6"` // 0 is Sunday
+	Start   string `json:"start" binding:"required"`      // "08:00"
+	End     string `json:"end" binding:"required"`        // "17:30", or "24:00" for end of day
+}
+
+type SetWorkerScheduleInput struct {
+	Slots []AvailabilitySlotInput `json:"slots" binding:"max=50,dive"`
+}
+
+// SetWorkerServices replaces the services the worker offers.
+func SetWorkerServices(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var input SetWorkerServicesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ids := uniqueUUIDs(input.ServiceIDs)
+	var services []models.Service
+	if len(ids) > 0 {
+		if err := config.DB.Where("id IN ? AND is_active = ?", ids, true).Find(&services).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update services")
+			return
+		}
+		if len(services) != len(ids) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Unknown or inactive service")
+			return
+		}
+	}
+
+	var before []uuid.UUID
+	config.DB.Table("worker_services").Where("worker_id = ?", worker.ID).Pluck("service_id", &before)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM worker_services WHERE worker_id = ?", worker.ID).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := tx.Exec("INSERT INTO worker_services (worker_id, service_id) VALUES (?, ?)", worker.ID, id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update services")
+		return
+	}
+
+	utils.AuditChange(c, "worker.services_updated", "worker", worker.ID.String(),
+		map[string]interface{}{"service_ids": before}, map[string]interface{}{"service_ids": ids})
+	utils.SuccessResponse(c, http.StatusOK, "Services updated", services)
+}
+
+// SetWorkerLanguages replaces the languages the worker speaks.
+func SetWorkerLanguages(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var input SetWorkerLanguagesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	languages := []models.WorkerLanguage{}
+	for _, language := range input.Languages {
+		language = strings.ToLower(language)
+		if !slices.ContainsFunc(languages, func(l models.WorkerLanguage) bool { return l.Language == language }) {
+			languages = append(languages, models.WorkerLanguage{WorkerID: worker.ID, Language: language})
+		}
+	}
+
+	var before []models.WorkerLanguage
+	config.DB.Where("worker_id = ?", worker.ID).Find(&before)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("worker_id = ?", worker.ID).Delete(&models.WorkerLanguage{}).Error; err != nil {
+			return err
+		}
+		if len(languages) == 0 {
+			return nil
+		}
+		return tx.Create(&languages).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update languages")
+		return
+	}
+
+	utils.AuditChange(c, "worker.languages_updated", "worker", worker.ID.String(),
+		map[string]interface{}{"languages": before}, map[string]interface{}{"languages": languages})
+	utils.SuccessResponse(c, http.StatusOK, "Languages updated", languages)
+}
+
+// SetWorkerSchedule replaces the worker's weekly availability. Times are in
+// APP_TIMEZONE and slots on the same day must not overlap.
+func SetWorkerSchedule(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var input SetWorkerScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slots, message := parseAvailabilitySlots(worker.ID, input.Slots)
+	if message != nil {
+		utils.ErrorResponsef(c, http.StatusBadRequest, message.Format, message.Args...)
+		return
+	}
+
+	var before []models.WorkerAvailabilitySlot
+	config.DB.Where("worker_id = ?", worker.ID).Find(&before)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("worker_id = ?", worker.ID).Delete(&models.WorkerAvailabilitySlot{}).Error; err != nil {
+			return err
+		}
+		if len(slots) == 0 {
+			return nil
+		}
+		return tx.Create(&slots).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update schedule")
+		return
+	}
+
+	utils.AuditChange(c, "worker.schedule_updated", "worker", worker.ID.String(),
+		map[string]interface{}{"slots": before}, map[string]interface{}{"slots": slots})
+	utils.SuccessResponse(c, http.StatusOK, "Schedule updated", slots)
+}
+
+// parseAvailabilitySlots converts "HH:MM" slots to minutes, sorted by day and
+// start time. A non-nil message is a client-facing error.
+func parseAvailabilitySlots(workerID uuid.UUID, inputs []AvailabilitySlotInput) ([]models.WorkerAvailabilitySlot, *utils.Message) {
+	slots := make([]models.WorkerAvailabilitySlot, 0, len(inputs))
+	for _, input := range inputs {
+		start, ok := parseClock(input.Start)
+		end, endOK := parseClock(input.End)
+		if !ok || !endOK {
+			return nil, utils.Messagef("Slot times must be in HH:MM format")
+		}
+		if start >= end {
+			return nil, utils.Messagef("Slot start must be before its end")
+		}
+		slots = append(slots, models.WorkerAvailabilitySlot{WorkerID: workerID, Weekday: input.Weekday, StartMinute: start, EndMinute: end})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Weekday != slots[j].Weekday {
+			return slots[i].Weekday < slots[j].Weekday
+		}
+		return slots[i].StartMinute < slots[j].StartMinute
+	})
+	for i := 1; i < len(slots); i++ {
+		if slots[i].Weekday == slots[i-1].Weekday && slots[i].StartMinute < slots[i-1].EndMinute {
+			return nil, utils.Messagef("Slots on the same day must not overlap")
+		}
+	}
+	return slots, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight; "24:00" is allowed
+// as the end of the day.
+func parseClock(s string) (int, bool) {
+	if s == "24:00" {
+		return 24 * 60, true
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func currentWorker(c *gin.Context) (models.Worker, bool) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var worker models.Worker
+	if err := config.DB.First(&worker, "user_id = ?", userID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Worker profile not found")
+		return worker, false
+	}
+	return worker, true
+}
+
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
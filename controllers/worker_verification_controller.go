@@ -0,0 +1,577 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxDraftDocuments caps the documents uploaded but not yet submitted.
+const maxDraftDocuments = 10
+
+var documentContentTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+
+var documentTypes = []models.DocumentType{
+	models.DocumentIDCard,
+	models.DocumentPassport,
+	models.DocumentDriverLicense,
+	models.DocumentCertificate,
+	models.DocumentOther,
+}
+
+var (
+	errVerificationUnderReview = errors.New("verification is already under review")
+	errVerificationNotPending  = errors.New("verification is not pending review")
+	errNoDraftDocuments        = errors.New("no documents to submit")
+	errIdentityDocumentMissing = errors.New("identity document required")
+	errOwnVerification         = errors.New("reviewer is the worker")
+	errDocumentExpired         = errors.New("a document has expired")
+)
+
+type VerificationDecisionInput struct {
+	Reason string `json:"reason" binding:"max=2000"` // required when rejecting or requesting info
+}
+
+// WorkerVerificationOverview is what a worker sees of their verification:
+// the current state, documents not yet submitted and every past review.
+type WorkerVerificationOverview struct {
+	Status        models.VerificationStatus   `json:"status"`
+	IsVerified    bool                        `json:"is_verified"`
+	VerifiedUntil *time.Time                  `json:"verified_until,omitempty"`
+	Drafts        []models.WorkerDocument     `json:"drafts"`
+	History       []models.WorkerVerification `json:"history"`
+}
+
+func GetMyVerification(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	overview := WorkerVerificationOverview{
+		Status:        worker.VerificationStatus,
+		IsVerified:    worker.IsVerified,
+		VerifiedUntil: worker.VerifiedUntil,
+		Drafts:        []models.WorkerDocument{},
+		History:       []models.WorkerVerification{},
+	}
+	if err := config.DB.Where("worker_id = ? AND verification_id IS NULL", worker.ID).
+		Order("created_at ASC").Find(&overview.Drafts).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch verification")
+		return
+	}
+	if err := config.DB.Preload("Documents").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("worker_id = ?", worker.ID).Order("created_at DESC").
+		Find(&overview.History).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch verification")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification retrieved", overview)
+}
+
+// UploadVerificationDocument stores a document as a draft. Form fields:
+// file, type and optionally expires_on (YYYY-MM-DD).
+func UploadVerificationDocument(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	maxBytes := int64(config.AppConfig.MaxUploadMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20) // room for the other form fields
+
+	docType := models.DocumentType(c.PostForm("type"))
+	if !slices.Contains(documentTypes, docType) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid document type")
+		return
+	}
+
+	var expiresOn *time.Time
+	if raw := c.PostForm("expires_on"); raw != "" {
+		date, err := time.ParseInLocation(time.DateOnly, raw, config.AppConfig.Location)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "expires_on must be a date in YYYY-MM-DD format")
+			return
+		}
+		if !date.After(time.Now()) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Document has already expired")
+			return
+		}
+		expiresOn = &date
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "A file is required")
+		return
+	}
+	if header.Size > maxBytes {
+		utils.ErrorResponsef(c, http.StatusRequestEntityTooLarge, "File must not exceed %d MB", config.AppConfig.MaxUploadMB)
+		return
+	}
+
+	var drafts int64
+	config.DB.Model(&models.WorkerDocument{}).Where("worker_id = ? AND verification_id IS NULL", worker.ID).Count(&drafts)
+	if drafts >= maxDraftDocuments {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Too many documents awaiting submission")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "A file is required")
+		return
+	}
+	defer file.Close()
+
+	// Trust the content, not the client's declared type
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !slices.Contains(documentContentTypes, contentType) {
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Documents must be JPEG, PNG or PDF files")
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to store document")
+		return
+	}
+
+	document := models.WorkerDocument{
+		ID:          uuid.New(),
+		WorkerID:    worker.ID,
+		Type:        docType,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		ExpiresOn:   expiresOn,
+	}
+	document.StorageKey = fmt.Sprintf("worker-documents/%s/%s", worker.ID, document.ID)
+
+	hash := sha256.New()
+	if err := utils.Blobs.Put(document.StorageKey, io.TeeReader(file, hash)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to store document")
+		return
+	}
+	document.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := config.DB.Create(&document).Error; err != nil {
+		utils.Blobs.Delete(document.StorageKey)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to store document")
+		return
+	}
+
+	utils.AuditChange(c, "worker.document_uploaded", "worker", worker.ID.String(), nil, document)
+	utils.SuccessResponse(c, http.StatusCreated, "Document uploaded", document)
+}
+
+// DeleteVerificationDocument removes a draft. Submitted documents are kept
+// as evidence for the review.
+func DeleteVerificationDocument(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	var document models.WorkerDocument
+	if err := config.DB.First(&document, "id = ? AND worker_id = ?", documentID, worker.ID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Document not found")
+		return
+	}
+	if document.VerificationID != nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Submitted documents cannot be deleted")
+		return
+	}
+
+	if err := config.DB.Delete(&document).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete document")
+		return
+	}
+	if err := utils.Blobs.Delete(document.StorageKey); err != nil {
+		log.Printf("Failed to delete blob %s: %v", document.StorageKey, err)
+	}
+
+	utils.AuditChange(c, "worker.document_deleted", "worker", worker.ID.String(), document, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Document deleted", nil)
+}
+
+func DownloadMyVerificationDocument(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	var document models.WorkerDocument
+	if err := config.DB.First(&document, "id = ? AND worker_id = ?", documentID, worker.ID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Document not found")
+		return
+	}
+	serveDocument(c, document)
+}
+
+// SubmitVerification sends the draft documents for review. A first
+// submission or renewal needs an identity document; answering a request
+// for more information needs at least one new document.
+func SubmitVerification(c *gin.Context) {
+	worker, ok := currentWorker(c)
+	if !ok {
+		return
+	}
+
+	var verification models.WorkerVerification
+	var resubmitted bool
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM workers WHERE id = ? FOR UPDATE", worker.ID).Error; err != nil {
+			return err
+		}
+
+		var drafts []models.WorkerDocument
+		if err := tx.Where("worker_id = ? AND verification_id IS NULL", worker.ID).Find(&drafts).Error; err != nil {
+			return err
+		}
+		if len(drafts) == 0 {
+			return errNoDraftDocuments
+		}
+
+		err := tx.Where("worker_id = ? AND status IN ?", worker.ID,
+			[]models.VerificationStatus{models.VerificationPending, models.VerificationInfoRequested}).
+			First(&verification).Error
+		switch {
+		case err == nil && verification.Status == models.VerificationPending:
+			return errVerificationUnderReview
+		case err == nil:
+			resubmitted = true
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if !slices.ContainsFunc(drafts, func(d models.WorkerDocument) bool { return d.Type.IsIdentity() }) {
+				return errIdentityDocumentMissing
+			}
+			verification = models.WorkerVerification{WorkerID: worker.ID}
+		default:
+			return err
+		}
+
+		verification.Status = models.VerificationPending
+		verification.SubmittedAt = time.Now()
+		if err := tx.Save(&verification).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.WorkerDocument{}).Where("worker_id = ? AND verification_id IS NULL", worker.ID).
+			Update("verification_id", verification.ID).Error; err != nil {
+			return err
+		}
+		if err := recordVerificationEvent(tx, c, verification, ""); err != nil {
+			return err
+		}
+		return tx.Model(&models.Worker{}).Where("id = ?", worker.ID).
+			Update("verification_status", models.VerificationPending).Error
+	})
+	switch {
+	case errors.Is(err, errNoDraftDocuments):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Upload your documents before submitting")
+		return
+	case errors.Is(err, errIdentityDocumentMissing):
+		utils.ErrorResponse(c, http.StatusBadRequest, "An ID card, passport or driver's license is required")
+		return
+	case errors.Is(err, errVerificationUnderReview):
+		utils.ErrorResponse(c, http.StatusConflict, "Verification is already under review")
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to submit verification")
+		return
+	}
+
+	action := "worker.verification_submitted"
+	if resubmitted {
+		action = "worker.verification_resubmitted"
+	}
+	utils.AuditChangeWithMetadata(c, action, "worker", worker.ID.String(), nil, verification,
+		map[string]interface{}{"verification_id": verification.ID})
+	utils.SuccessResponse(c, http.StatusOK, "Verification submitted", verification)
+}
+
+var verificationSorts = map[string]utils.SortField{
+	"submitted_at": {Column: "submitted_at", Type: utils.SortTime},
+	"created_at":   {Column: "created_at", Type: utils.SortTime},
+}
+
+var verificationStatusValues = []string{
+	string(models.VerificationPending),
+	string(models.VerificationInfoRequested),
+	string(models.VerificationApproved),
+	string(models.VerificationRejected),
+	string(models.VerificationExpired),
+}
+
+// AdminListVerifications is the review queue: pending verifications, oldest
+// submission first. Pass status to see other states.
+func AdminListVerifications(c *gin.Context) {
+	page, err := utils.ParsePageQuery(c, verificationSorts, "submitted_at", "id")
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+
+	statuses, err := utils.ParseSet(c, "status", verificationStatusValues)
+	if err != nil {
+		utils.ParamErrorResponse(c, err)
+		return
+	}
+	if len(statuses) == 0 {
+		statuses = []string{string(models.VerificationPending)}
+	}
+
+	var verifications []models.WorkerVerification
+	query := config.DB.Preload("Worker.User").Where("status IN ?", statuses)
+	if err := page.Apply(query).Find(&verifications).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch verifications")
+		return
+	}
+
+	verifications, meta := utils.Page(page, verifications, func(v models.WorkerVerification) (interface{}, uuid.UUID) {
+		if page.SortKey == "created_at" {
+			return v.CreatedAt, v.ID
+		}
+		return v.SubmittedAt, v.ID
+	})
+	utils.PagedResponse(c, http.StatusOK, "Verifications retrieved", verifications, meta)
+}
+
+func AdminGetVerification(c *gin.Context) {
+	verificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid verification ID")
+		return
+	}
+
+	var verification models.WorkerVerification
+	if err := config.DB.Preload("Worker.User").Preload("Documents").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&verification, "id = ?", verificationID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Verification not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification retrieved", verification)
+}
+
+func AdminDownloadVerificationDocument(c *gin.Context) {
+	verificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid verification ID")
+		return
+	}
+	documentID, err := uuid.Parse(c.Param("documentId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	var document models.WorkerDocument
+	if err := config.DB.First(&document, "id = ? AND verification_id = ?", documentID, verificationID).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	utils.AuditChangeWithMetadata(c, "worker.document_viewed", "worker", document.WorkerID.String(), nil, nil,
+		map[string]interface{}{"document_id": document.ID})
+	serveDocument(c, document)
+}
+
+// ApproveVerification verifies the worker until VERIFICATION_VALIDITY_DAYS
+// from now, or until the earliest document expiry if that comes first.
+func ApproveVerification(c *gin.Context) {
+	decideVerification(c, models.VerificationApproved)
+}
+
+func RejectVerification(c *gin.Context) {
+	decideVerification(c, models.VerificationRejected)
+}
+
+// RequestVerificationInfo sends the verification back to the worker, who
+// uploads more documents and resubmits.
+func RequestVerificationInfo(c *gin.Context) {
+	decideVerification(c, models.VerificationInfoRequested)
+}
+
+func decideVerification(c *gin.Context, status models.VerificationStatus) {
+	reviewerID := c.MustGet("userID").(uuid.UUID)
+
+	var input VerificationDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if status != models.VerificationApproved && input.Reason == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "A reason is required")
+		return
+	}
+	verificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid verification ID")
+		return
+	}
+
+	var verification, before models.WorkerVerification
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM worker_verifications WHERE id = ? FOR UPDATE", verificationID).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Documents").First(&verification, "id = ?", verificationID).Error; err != nil {
+			return err
+		}
+		if verification.Status != models.VerificationPending {
+			return errVerificationNotPending
+		}
+		var own int64
+		if err := tx.Model(&models.Worker{}).Where("id = ? AND user_id = ?", verification.WorkerID, reviewerID).Count(&own).Error; err != nil {
+			return err
+		}
+		if own > 0 {
+			return errOwnVerification
+		}
+
+		before = verification
+		now := time.Now()
+		verification.Status = status
+		verification.ReviewerID = &reviewerID
+		verification.ReviewedAt = &now
+		verification.Reason = input.Reason
+
+		workerUpdates := map[string]interface{}{"verification_status": status}
+		if status == models.VerificationApproved {
+			expiresAt := now.AddDate(0, 0, config.AppConfig.VerificationValidityDays)
+			for _, document := range verification.Documents {
+				if document.ExpiresOn != nil && !document.ExpiresOn.After(now) {
+					return errDocumentExpired
+				}
+				if document.ExpiresOn != nil && document.ExpiresOn.Before(expiresAt) {
+					expiresAt = *document.ExpiresOn
+				}
+			}
+			verification.ExpiresAt = &expiresAt
+			workerUpdates["is_verified"] = true
+			workerUpdates["verified_until"] = expiresAt
+		}
+
+		if err := tx.Omit("Documents", "Events").Save(&verification).Error; err != nil {
+			return err
+		}
+		if err := recordVerificationEvent(tx, c, verification, input.Reason); err != nil {
+			return err
+		}
+		return tx.Model(&models.Worker{}).Where("id = ?", verification.WorkerID).Updates(workerUpdates).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Verification not found")
+		return
+	case errors.Is(err, errVerificationNotPending):
+		utils.ErrorResponse(c, http.StatusConflict, "Verification is not pending review")
+		return
+	case errors.Is(err, errOwnVerification):
+		utils.ErrorResponse(c, http.StatusForbidden, "You cannot review your own verification")
+		return
+	case errors.Is(err, errDocumentExpired):
+		utils.ErrorResponse(c, http.StatusConflict, "Document has already expired")
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update verification")
+		return
+	}
+
+	utils.AuditChange(c, "worker.verification_"+string(status), "worker", verification.WorkerID.String(), before, verification)
+
+	if err := sendVerificationDecision(verification); err != nil {
+		log.Printf("Failed to notify worker %s of verification %s: %v", verification.WorkerID, verification.ID, err)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification updated", verification)
+}
+
+func recordVerificationEvent(tx *gorm.DB, c *gin.Context, verification models.WorkerVerification, reason string) error {
+	actorID := c.MustGet("userID").(uuid.UUID)
+	return tx.Create(&models.WorkerVerificationEvent{
+		VerificationID: verification.ID,
+		Status:         verification.Status,
+		Reason:         reason,
+		ActorID:        &actorID,
+	}).Error
+}
+
+func sendVerificationDecision(verification models.WorkerVerification) error {
+	var worker models.Worker
+	if err := config.DB.Preload("User").First(&worker, "id = ?", verification.WorkerID).Error; err != nil {
+		return err
+	}
+
+	var subject, body string
+	switch verification.Status {
+	case models.VerificationApproved:
+		subject = "You are verified"
+		body = fmt.Sprintf("Hi %s,\n\nYour documents have been approved. Your verification is valid until %s.",
+			worker.User.Name, verification.ExpiresAt.In(config.AppConfig.Location).Format("2 Jan 2006"))
+	case models.VerificationRejected:
+		subject = "Your verification was not approved"
+		body = fmt.Sprintf("Hi %s,\n\nWe could not approve your documents:\n\n%s\n\nYou can upload new documents and submit again.",
+			worker.User.Name, verification.Reason)
+	default:
+		subject = "We need more information"
+		body = fmt.Sprintf("Hi %s,\n\nWe need more information to finish reviewing your documents:\n\n%s\n\nPlease upload the requested documents and resubmit.",
+			worker.User.Name, verification.Reason)
+	}
+	return utils.Mailer.SendEmail(worker.User.Email, subject, body)
+}
+
+// serveDocument streams a document as an attachment. Documents hold
+// personal data, so they are never cached.
+func serveDocument(c *gin.Context, document models.WorkerDocument) {
+	blob, err := utils.Blobs.Open(document.StorageKey)
+	if err != nil {
+		if errors.Is(err, utils.ErrBlobNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Document not found")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to read document")
+		}
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, blob, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", document.FileName),
+	})
+}
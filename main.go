@@ -2,10 +2,12 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/DucLUT/goodstuff/config"
 	"github.com/DucLUT/goodstuff/models"
 	"github.com/DucLUT/goodstuff/routes"
+	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,9 @@ func main() {
 
 	// Load configuration
 	config.Load()
+	if err := config.AppConfig.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Set Gin mode
 	gin.SetMode(config.AppConfig.GinMode)
@@ -29,11 +34,74 @@ func main() {
 	config.AutoMigrate(
 		&models.User{},
 		&models.Worker{},
+		&models.WorkerLanguage{},
+		&models.WorkerAvailabilitySlot{},
 		&models.ServiceCategory{},
 		&models.Service{},
+		&models.ServiceOption{},
+		&models.ServiceOptionChoice{},
+		&models.ServiceTranslation{},
+		&models.CategoryTranslation{},
 		&models.Booking{},
 		&models.Review{},
+		&models.ReviewReport{},
+		&models.ReviewModerationEvent{},
+		&models.SigningKey{},
+		&models.VerificationToken{},
+		&models.LoginAttempt{},
+		&models.AuditLog{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.UserRoleAssignment{},
+		&models.ImpersonationSession{},
+		&models.Address{},
+		&models.WorkerPreference{},
+		&models.WorkerDocument{},
+		&models.WorkerVerification{},
+		&models.WorkerVerificationEvent{},
+		&models.Media{},
+		&models.BookingCheckpoint{},
+		&models.CustomerReview{},
 	)
+	config.RunMigrations()
+
+	// Built-in staff roles
+	if err := utils.SeedSystemRoles(); err != nil {
+		log.Fatalf("Failed to seed system roles: %v", err)
+	}
+
+	// Load token signing keys and rotate them on schedule
+	if err := utils.InitSigningKeys(); err != nil {
+		log.Fatalf("Failed to initialize signing keys: %v", err)
+	}
+	utils.RunPeriodically("signing key rotation", 5*time.Minute, utils.RotateSigningKeys)
+
+	// Email and SMS delivery
+	utils.InitNotifiers()
+
+	// Uploaded file storage
+	utils.InitBlobStore()
+	utils.InitMedia()
+
+	// Social login providers
+	utils.InitOIDCProviders()
+
+	// Login brute-force protection
+	utils.InitLoginThrottles()
+	utils.RunPeriodically("login attempt pruning", 10*time.Minute, func() error {
+		return utils.AccountLoginThrottle.Store.Prune(time.Now().Add(-time.Hour))
+	})
+
+	// Anonymize accounts whose deletion grace period has ended
+	utils.RunPeriodically("account anonymization", time.Hour, utils.AnonymizeDeletedAccounts)
+
+	// Audit log retention
+	utils.RunPeriodically("audit log purge", time.Hour, utils.PurgeAuditLog)
+
+	// Lapse worker verifications that were not renewed in time
+	utils.RunPeriodically("worker verification expiry", time.Hour, utils.ExpireWorkerVerifications)
 
 	// Setup router
 	r := routes.SetupRouter()
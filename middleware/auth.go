@@ -3,7 +3,10 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
 	"github.com/DucLUT/goodstuff/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -25,14 +28,88 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		claims, err := utils.ValidateToken(parts[1])
-		if err != nil {
+		if err != nil || (claims.TokenUse != utils.TokenUseSession && claims.TokenUse != utils.TokenUseImpersonation) {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
 			c.Abort()
 			return
 		}
 
+		// Tokens are revoked by bumping the user's session version
+		var user models.User
+		if err := config.DB.Select("id", "is_active", "session_version").First(&user, "id = ?", claims.UserID).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
+			c.Abort()
+			return
+		}
+
+		if !user.IsActive || user.SessionVersion != claims.SessionVersion {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Session has been revoked")
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("userRole", claims.Role)
+
+		if claims.TokenUse == utils.TokenUseImpersonation {
+			session, ok := activeImpersonation(claims)
+			if !ok {
+				utils.ErrorResponse(c, http.StatusUnauthorized, "Impersonation session has ended")
+				c.Abort()
+				return
+			}
+			c.Set("impersonatorID", session.ImpersonatorID)
+			c.Set("impersonationSessionID", session.ID)
+
+			c.Next()
+
+			// Every request made while impersonating is kept on record
+			utils.RecordAudit(c, "impersonation.request", "impersonation_session", session.ID.String(), map[string]interface{}{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+				"status": c.Writer.Status(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// activeImpersonation checks that an impersonation token belongs to a session
+// that has not been ended and whose admin still has an active account.
+func activeImpersonation(claims *utils.Claims) (*models.ImpersonationSession, bool) {
+	if claims.Actor == nil {
+		return nil, false
+	}
+
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, "id = ?", claims.ID).Error; err != nil {
+		return nil, false
+	}
+
+	if !session.IsActive(time.Now()) || session.UserID != claims.UserID || session.ImpersonatorID.String() != claims.Actor.Subject {
+		return nil, false
+	}
+
+	var impersonator models.User
+	if err := config.DB.Select("id", "is_active").First(&impersonator, "id = ?", session.ImpersonatorID).Error; err != nil || !impersonator.IsActive {
+		return nil, false
+	}
+
+	return &session, true
+}
+
+// BlockImpersonation rejects sensitive account actions, such as changing
+// credentials, when the request is made by an admin impersonating the user.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonating := c.Get("impersonatorID"); impersonating {
+			utils.ErrorResponse(c, http.StatusForbidden, "This action is not available while impersonating a user")
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -58,3 +135,65 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// RequireVerifiedAccount blocks users who have not confirmed both their email
+// address and phone number, or whose account is pending deletion.
+func RequireVerifiedAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.Select("id", "email_verified_at", "phone_verified_at", "deletion_scheduled_for").First(&user, "id = ?", userID).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+
+		if !user.IsVerified() {
+			utils.ErrorResponse(c, http.StatusForbidden, "Please verify your email address and phone number first")
+			c.Abort()
+			return
+		}
+
+		if user.DeletionScheduledFor != nil {
+			utils.ErrorResponse(c, http.StatusForbidden, "Your account is scheduled for deletion, restore it to continue")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireTwoFactor only lets through users who have two-factor authentication
+// enabled. Used to make 2FA mandatory for privileged areas.
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.Select("id", "two_factor_enabled").First(&user, "id = ?", userID).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+
+		if !user.TwoFactorEnabled {
+			utils.ErrorResponse(c, http.StatusForbidden, "Two-factor authentication must be enabled for this account")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
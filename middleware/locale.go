@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale negotiates the response language from ?lang= or Accept-Language
+// and stores it on the context for handlers and error messages.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := utils.NegotiateLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+
+		c.Set("locale", locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/DucLUT/goodstuff/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through if the user holds the
+// permission in at least one scope. Handlers still check the scope against
+// the resource they touch.
+func RequirePermission(perm models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, err := utils.CurrentPermissions(c)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load permissions")
+			c.Abort()
+			return
+		}
+
+		if !permissions.Has(perm) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Access denied")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Address is an entry in a customer's address book.
+type Address struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID      `gorm:"type:uuid;not null;index;uniqueIndex:idx_addresses_one_default,where:is_default AND deleted_at IS NULL" json:"user_id"`
+	Label       string         `gorm:"type:varchar(64);not null" json:"label"` // e.g. "Home", "Office"
+	Street      string         `gorm:"not null" json:"street"`
+	Ward        string         `json:"ward,omitempty"`
+	District    string         `json:"district,omitempty"`
+	City        string         `gorm:"not null" json:"city"`
+	Country     string         `gorm:"type:varchar(64);not null" json:"country"`
+	PostalCode  string         `gorm:"type:varchar(16)" json:"postal_code,omitempty"`
+	Latitude    *float64       `json:"latitude,omitempty"`
+	Longitude   *float64       `json:"longitude,omitempty"`
+	AccessNotes string         `gorm:"type:text" json:"access_notes,omitempty"` // gate codes, parking, etc.
+	IsDefault   bool           `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (a *Address) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Snapshot copies the address as it is now, for storing on a booking.
+func (a *Address) Snapshot() BookingAddress {
+	return BookingAddress{
+		Label:       a.Label,
+		Street:      a.Street,
+		Ward:        a.Ward,
+		District:    a.District,
+		City:        a.City,
+		Country:     a.Country,
+		PostalCode:  a.PostalCode,
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+		AccessNotes: a.AccessNotes,
+	}
+}
+
+// BookingAddress is the copy of a saved address kept on a booking, so later
+// edits to the address book do not rewrite booking history.
+type BookingAddress struct {
+	Label       string   `gorm:"type:varchar(64)" json:"label,omitempty"`
+	Street      string   `json:"street,omitempty"`
+	Ward        string   `json:"ward,omitempty"`
+	District    string   `json:"district,omitempty"`
+	City        string   `json:"city,omitempty"`
+	Country     string   `gorm:"type:varchar(64)" json:"country,omitempty"`
+	PostalCode  string   `gorm:"type:varchar(16)" json:"postal_code,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	AccessNotes string   `gorm:"type:text" json:"access_notes,omitempty"`
+}
+
+// Approximate keeps only the area and coordinates rounded to about a
+// kilometre, for workers who have not been assigned the booking yet.
+func (a BookingAddress) Approximate() BookingAddress {
+	round := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		r := math.Round(*v*100) / 100
+		return &r
+	}
+	return BookingAddress{
+		Ward:      a.Ward,
+		District:  a.District,
+		City:      a.City,
+		Country:   a.Country,
+		Latitude:  round(a.Latitude),
+		Longitude: round(a.Longitude),
+	}
+}
+
+// Formatted renders the address on a single line.
+func (a BookingAddress) Formatted() string {
+	var parts []string
+	for _, part := range []string{a.Street, a.Ward, a.District, a.City, a.PostalCode, a.Country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
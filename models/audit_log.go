@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog is append-only; a database trigger rejects updates and deletes
+// other than the retention purge. Before and After hold only the fields a
+// change touched.
+type AuditLog struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID        *uuid.UUID `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	ImpersonatorID *uuid.UUID `gorm:"type:uuid;index" json:"impersonator_id,omitempty"` // admin behind an impersonated session
+	Action         string     `gorm:"type:varchar(64);not null;index" json:"action"`
+	ResourceType   string     `gorm:"type:varchar(64);index:idx_audit_logs_resource" json:"resource_type,omitempty"`
+	ResourceID     string     `gorm:"type:varchar(320);index:idx_audit_logs_resource" json:"resource_id,omitempty"`
+	RequestID      string     `gorm:"type:varchar(64);index" json:"request_id,omitempty"`
+	Method         string     `gorm:"type:varchar(8)" json:"method,omitempty"`
+	Path           string     `gorm:"type:text" json:"path,omitempty"`
+	IPAddress      string     `gorm:"type:varchar(64)" json:"ip_address,omitempty"`
+	UserAgent      string     `gorm:"type:text" json:"user_agent,omitempty"`
+	Before         JSON       `gorm:"type:jsonb" json:"before,omitempty"`
+	After          JSON       `gorm:"type:jsonb" json:"after,omitempty"`
+	Metadata       JSON       `gorm:"type:jsonb" json:"metadata,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
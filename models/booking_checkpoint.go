@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheckpointKind string
+
+const (
+	CheckpointCheckIn  CheckpointKind = "check_in"
+	CheckpointCheckOut CheckpointKind = "check_out"
+)
+
+// BookingCheckpoint is the evidence a worker leaves when starting or
+// completing a job: where they were and photos of the work.
+type BookingCheckpoint struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BookingID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_booking_checkpoints_kind" json:"booking_id"`
+	Kind      CheckpointKind `gorm:"type:varchar(16);not null;uniqueIndex:idx_booking_checkpoints_kind" json:"kind"`
+	WorkerID  uuid.UUID      `gorm:"type:uuid;not null" json:"worker_id"`
+	Latitude  *float64       `json:"latitude,omitempty"`
+	Longitude *float64       `json:"longitude,omitempty"`
+	Accuracy  *float64       `json:"accuracy_meters,omitempty"` // as reported by the device
+	// Distance from the booking address; nil when either location is unknown
+	DistanceMeters *float64  `json:"distance_meters,omitempty"`
+	Note           string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	Photos         []Media   `gorm:"foreignKey:CheckpointID" json:"photos,omitempty"`
+}
+
+func (cp *BookingCheckpoint) BeforeCreate(tx *gorm.DB) error {
+	if cp.ID == uuid.Nil {
+		cp.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomerReview is a worker's rating of the customer after a completed
+// booking. The ratings make up the customer's reliability score.
+type CustomerReview struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BookingID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"booking_id"`
+	Booking    *Booking       `gorm:"foreignKey:BookingID" json:"booking,omitempty"`
+	CustomerID uuid.UUID      `gorm:"type:uuid;not null;index" json:"customer_id"`
+	WorkerID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"worker_id"`
+	Worker     *Worker        `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
+	Rating     int            `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
+	Comment    string         `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *CustomerReview) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationSession records an admin acting as another user. The token
+// minted for it carries the session ID as its jti so it can be ended early.
+type ImpersonationSession struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ImpersonatorID uuid.UUID  `gorm:"type:uuid;not null;index" json:"impersonator_id"`
+	Impersonator   User       `gorm:"foreignKey:ImpersonatorID" json:"impersonator,omitempty"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User           User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Reason         string     `gorm:"type:text;not null" json:"reason"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	EndedBy        *uuid.UUID `gorm:"type:uuid" json:"ended_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (s *ImpersonationSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether tokens for the session are still honoured.
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
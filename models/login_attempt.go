@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt tracks recent failed logins for a throttling key such as
+// "ip:1.2.3.4" or "account:user@example.com".
+type LoginAttempt struct {
+	Key           string     `gorm:"type:varchar(320);primary_key" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null;index" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
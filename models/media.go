@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MediaPurpose string
+
+const (
+	MediaAvatar       MediaPurpose = "avatar"
+	MediaServiceImage MediaPurpose = "service_image"
+	MediaCategoryIcon MediaPurpose = "category_icon"
+	MediaJobPhoto     MediaPurpose = "job_photo"
+)
+
+type MediaVariant string
+
+const (
+	MediaOriginal  MediaVariant = "original"
+	MediaThumbnail MediaVariant = "thumbnail"
+)
+
+// Media is an uploaded image. Blobs are stored by content hash, so several
+// media rows may share the same files.
+type Media struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OwnerID       uuid.UUID    `gorm:"type:uuid;not null;index" json:"owner_id"` // uploader
+	Purpose       MediaPurpose `gorm:"type:varchar(32);not null" json:"purpose"`
+	BookingID     *uuid.UUID   `gorm:"type:uuid;index" json:"booking_id,omitempty"`    // job photos only
+	CheckpointID  *uuid.UUID   `gorm:"type:uuid;index" json:"checkpoint_id,omitempty"` // taken at check-in or check-out
+	SHA256        string       `gorm:"type:char(64);not null;index" json:"sha256"`
+	ContentType   string       `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size          int64        `gorm:"not null" json:"size"`
+	Width         int          `gorm:"not null" json:"width"`
+	Height        int          `gorm:"not null" json:"height"`
+	StorageKey    string       `gorm:"not null" json:"-"`
+	ThumbnailKey  string       `gorm:"not null" json:"-"` // same as StorageKey for small images
+	ThumbnailType string       `gorm:"type:varchar(100);not null" json:"-"`
+	CreatedAt     time.Time    `json:"created_at"`
+
+	URL          string `gorm:"-" json:"url"`
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url"`
+}
+
+// MediaURL returns a signed, expiring URL for a media variant. It is set at
+// startup so loaded models can carry ready-to-use links.
+var MediaURL func(id uuid.UUID, variant MediaVariant) string
+
+func (m *Media) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+func (m *Media) AfterFind(tx *gorm.DB) error {
+	m.URL, m.ThumbnailURL = mediaURLs(&m.ID)
+	return nil
+}
+
+// mediaURLs returns empty links when id is nil or signing is not set up.
+func mediaURLs(id *uuid.UUID) (original, thumbnail string) {
+	if id == nil || MediaURL == nil {
+		return "", ""
+	}
+	return MediaURL(*id, MediaOriginal), MediaURL(*id, MediaThumbnail)
+}
@@ -17,27 +17,42 @@ const (
 	StatusCancelled  BookingStatus = "cancelled"
 )
 
+// OpenBookingStatuses are the statuses of bookings that still need work.
+var OpenBookingStatuses = []BookingStatus{StatusPending, StatusConfirmed, StatusInProgress}
+
 type Booking struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	CustomerID    uuid.UUID      `gorm:"type:uuid;not null" json:"customer_id"`
-	Customer      User           `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
-	WorkerID      *uuid.UUID     `gorm:"type:uuid" json:"worker_id,omitempty"`
-	Worker        *Worker        `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
-	ServiceID     uuid.UUID      `gorm:"type:uuid;not null" json:"service_id"`
-	Service       Service        `gorm:"foreignKey:ServiceID" json:"service,omitempty"`
-	Status        BookingStatus  `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	ScheduledAt   time.Time      `gorm:"not null" json:"scheduled_at"`
-	DurationHours float64        `gorm:"not null" json:"duration_hours"`
-	Address       string         `gorm:"not null" json:"address"`
-	Notes         string         `gorm:"type:text" json:"notes,omitempty"`
-	TotalPrice    float64        `gorm:"not null" json:"total_price"`
-	StartedAt     *time.Time     `json:"started_at,omitempty"`
-	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
-	CancelledAt   *time.Time     `json:"cancelled_at,omitempty"`
-	CancelReason  string         `gorm:"type:text" json:"cancel_reason,omitempty"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CustomerID      uuid.UUID      `gorm:"type:uuid;not null" json:"customer_id"`
+	Customer        User           `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	WorkerID        *uuid.UUID     `gorm:"type:uuid" json:"worker_id,omitempty"`
+	Worker          *Worker        `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
+	ServiceID       uuid.UUID      `gorm:"type:uuid;not null" json:"service_id"`
+	Service         Service        `gorm:"foreignKey:ServiceID" json:"service,omitempty"`
+	Status          BookingStatus  `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ScheduledAt     time.Time      `gorm:"not null" json:"scheduled_at"`
+	DurationMinutes int            `gorm:"not null;default:0" json:"duration_minutes"`
+	Address         string         `gorm:"not null" json:"address"`
+	AddressID       *uuid.UUID     `gorm:"type:uuid" json:"address_id,omitempty"` // saved address the booking was made from
+	AddressDetails  BookingAddress `gorm:"embedded;embeddedPrefix:address_" json:"address_details"`
+	Region          string         `gorm:"type:varchar(64);index" json:"region,omitempty"`
+	Notes           string         `gorm:"type:text" json:"notes,omitempty"`
+	// A customer's favorite worker gets the booking to themselves until
+	// PreferredUntil; after that any worker may accept it.
+	PreferredWorkerID *uuid.UUID     `gorm:"type:uuid;index" json:"preferred_worker_id,omitempty"`
+	PreferredUntil    *time.Time     `json:"preferred_until,omitempty"`
+	Options           BookingOptions `gorm:"type:jsonb;not null;default:'[]'" json:"options"`
+	OptionsPrice      float64        `gorm:"not null;default:0" json:"options_price"` // included in total_price
+	TotalPrice        float64        `gorm:"not null" json:"total_price"`
+	AcceptedAt        *time.Time     `json:"accepted_at,omitempty"`
+	StartedAt         *time.Time     `json:"started_at,omitempty"`
+	CompletedAt       *time.Time     `json:"completed_at,omitempty"`
+	CancelledAt       *time.Time     `json:"cancelled_at,omitempty"`
+	CancelReason      string         `gorm:"type:text" json:"cancel_reason,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Checkpoints []BookingCheckpoint `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"checkpoints,omitempty"`
 }
 
 func (b *Booking) BeforeCreate(tx *gorm.DB) error {
@@ -7,15 +7,28 @@ import (
 	"gorm.io/gorm"
 )
 
+type ReviewStatus string
+
+const (
+	ReviewPublished ReviewStatus = "published"
+	ReviewHidden    ReviewStatus = "hidden" // by a moderator, excluded from the worker's rating
+)
+
 type Review struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	BookingID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"booking_id"`
 	Booking   Booking        `gorm:"foreignKey:BookingID" json:"booking,omitempty"`
 	Rating    int            `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
 	Comment   string         `gorm:"type:text" json:"comment,omitempty"`
+	Reply     string         `gorm:"type:text" json:"reply,omitempty"` // the worker's public answer, at most one
+	RepliedAt *time.Time     `json:"replied_at,omitempty"`
+	Status    ReviewStatus   `gorm:"type:varchar(16);not null;default:'published';index" json:"status"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Reports []ReviewReport          `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE" json:"reports,omitempty"`
+	Events  []ReviewModerationEvent `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE" json:"events,omitempty"`
 }
 
 func (r *Review) BeforeCreate(tx *gorm.DB) error {
@@ -24,3 +37,66 @@ func (r *Review) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+type ReportReason string
+
+const (
+	ReportAbusive      ReportReason = "abusive"
+	ReportSpam         ReportReason = "spam"
+	ReportPersonalData ReportReason = "personal_data"
+	ReportNotGenuine   ReportReason = "not_genuine"
+	ReportOther        ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportUpheld    ReportStatus = "upheld"    // the review was hidden
+	ReportDismissed ReportStatus = "dismissed" // the review stays published
+)
+
+// ReviewReport is a user's complaint about a review. Each user can report a
+// review once; open reports make up the moderation queue.
+type ReviewReport struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReviewID   uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_review_reports_reporter,priority:1" json:"review_id"`
+	ReporterID uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_review_reports_reporter,priority:2" json:"reporter_id"`
+	Reason     ReportReason `gorm:"type:varchar(32);not null" json:"reason"`
+	Details    string       `gorm:"type:text" json:"details,omitempty"`
+	Status     ReportStatus `gorm:"type:varchar(16);not null;default:'open';index" json:"status"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func (r *ReviewReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+type ModerationAction string
+
+const (
+	ModerationHidden           ModerationAction = "hidden"
+	ModerationRestored         ModerationAction = "restored"
+	ModerationReportsDismissed ModerationAction = "reports_dismissed"
+)
+
+// ReviewModerationEvent records each moderator decision with its reason.
+type ReviewModerationEvent struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReviewID  uuid.UUID        `gorm:"type:uuid;not null;index" json:"review_id"`
+	Action    ModerationAction `gorm:"type:varchar(32);not null" json:"action"`
+	Reason    string           `gorm:"type:text;not null" json:"reason"`
+	ActorID   uuid.UUID        `gorm:"type:uuid;not null" json:"actor_id"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (e *ReviewModerationEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
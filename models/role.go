@@ -0,0 +1,163 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Permission string
+
+const (
+	PermBookingsRead     Permission = "bookings.read"
+	PermBookingsManage   Permission = "bookings.manage"
+	PermBookingsRefund   Permission = "bookings.refund"
+	PermServicesManage   Permission = "services.manage"
+	PermCategoriesManage Permission = "categories.manage"
+	PermUsersRead        Permission = "users.read"
+	PermUsersManage      Permission = "users.manage"
+	PermUsersImpersonate Permission = "users.impersonate"
+	PermRolesManage      Permission = "roles.manage"
+	PermAuditRead        Permission = "audit.read"
+	PermWorkersVerify    Permission = "workers.verify"
+	PermReviewsModerate  Permission = "reviews.moderate"
+)
+
+// AllPermissions lists every permission that can be granted to a role.
+var AllPermissions = []Permission{
+	PermBookingsRead,
+	PermBookingsManage,
+	PermBookingsRefund,
+	PermServicesManage,
+	PermCategoriesManage,
+	PermUsersRead,
+	PermUsersManage,
+	PermUsersImpersonate,
+	PermRolesManage,
+	PermAuditRead,
+	PermWorkersVerify,
+	PermReviewsModerate,
+}
+
+func IsKnownPermission(p Permission) bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a named set of permissions that can be assigned to staff accounts.
+type Role struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string           `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+	Description string           `gorm:"type:text" json:"description"`
+	IsSystem    bool             `gorm:"not null;default:false" json:"is_system"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"permissions"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+type RolePermission struct {
+	RoleID     uuid.UUID  `gorm:"type:uuid;primary_key" json:"-"`
+	Permission Permission `gorm:"type:varchar(64);primary_key" json:"permission"`
+}
+
+// Scope restricts an assignment to resources whose attributes match, e.g.
+// {"region": "hanoi"}. An empty scope applies to every resource. Values on
+// both sides are stored normalized, see utils.NormalizeScopeValue.
+type Scope map[string]string
+
+func (s Scope) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(s)
+	return string(raw), err
+}
+
+func (s *Scope) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for Scope column")
+	}
+	return json.Unmarshal(raw, s)
+}
+
+// Matches reports whether a resource with the given attributes falls inside
+// the scope.
+func (s Scope) Matches(attributes map[string]string) bool {
+	for key, value := range s {
+		if attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+type UserRoleAssignment struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RoleID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"role_id"`
+	Role      Role       `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"role,omitempty"`
+	Scope     Scope      `gorm:"type:jsonb;not null;default:'{}'" json:"scope"`
+	GrantedBy *uuid.UUID `gorm:"type:uuid" json:"granted_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (a *UserRoleAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// SystemRoles are created at startup if missing. Their permissions can be
+// edited but the roles themselves cannot be deleted.
+var SystemRoles = []Role{
+	{
+		Name:        "support_agent",
+		Description: "Views bookings and customers, issues refunds and impersonates customers",
+		Permissions: []RolePermission{{Permission: PermBookingsRead}, {Permission: PermBookingsRefund}, {Permission: PermUsersRead}, {Permission: PermUsersImpersonate}},
+	},
+	{
+		Name:        "city_manager",
+		Description: "Manages bookings; assign with a region scope",
+		Permissions: []RolePermission{{Permission: PermBookingsRead}, {Permission: PermBookingsManage}},
+	},
+	{
+		Name:        "catalog_manager",
+		Description: "Maintains services and categories",
+		Permissions: []RolePermission{{Permission: PermServicesManage}, {Permission: PermCategoriesManage}},
+	},
+	{
+		Name:        "verification_agent",
+		Description: "Reviews worker identity documents and certificates",
+		Permissions: []RolePermission{{Permission: PermWorkersVerify}},
+	},
+	{
+		Name:        "review_moderator",
+		Description: "Handles reported reviews, hiding or restoring them",
+		Permissions: []RolePermission{{Permission: PermReviewsModerate}},
+	},
+}
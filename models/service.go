@@ -8,20 +8,26 @@ import (
 )
 
 type Service struct {
-	ID           uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	CategoryID   uuid.UUID       `gorm:"type:uuid;not null" json:"category_id"`
-	Category     ServiceCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Name         string          `gorm:"not null" json:"name"`
-	Description  string          `gorm:"type:text" json:"description"`
-	BasePrice    float64         `gorm:"not null" json:"base_price"`
-	PricePerHour float64         `gorm:"not null" json:"price_per_hour"`
-	MinDuration  int             `gorm:"default:60" json:"min_duration"`  // minutes
-	MaxDuration  int             `gorm:"default:480" json:"max_duration"` // minutes
-	Image        string          `json:"image,omitempty"`
-	IsActive     bool            `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CategoryID     uuid.UUID       `gorm:"type:uuid;not null" json:"category_id"`
+	Category       ServiceCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Name           string          `gorm:"not null" json:"name"`
+	Description    string          `gorm:"type:text" json:"description"`
+	BasePrice      float64         `gorm:"not null" json:"base_price"`
+	PricePerHour   float64         `gorm:"not null" json:"price_per_hour"`
+	MinDuration    int             `gorm:"default:60" json:"min_duration"`           // minutes
+	MaxDuration    int             `gorm:"default:480" json:"max_duration"`          // minutes
+	DurationStep   int             `gorm:"not null;default:30" json:"duration_step"` // minutes; bookable durations are min_duration plus whole steps
+	ImageMediaID   *uuid.UUID      `gorm:"type:uuid" json:"image_media_id,omitempty"`
+	LegacyImage    string          `gorm:"column:image" json:"-"`    // client-supplied URL from before uploads
+	Image          string          `gorm:"-" json:"image,omitempty"` // signed URL, or the legacy URL
+	ImageThumbnail string          `gorm:"-" json:"image_thumbnail,omitempty"`
+	IsActive       bool            `gorm:"default:true" json:"is_active"`
+	SortOrder      int             `gorm:"not null;default:0" json:"sort_order"` // lower comes first
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+	Options        []ServiceOption `gorm:"foreignKey:ServiceID;constraint:OnDelete:CASCADE" json:"options,omitempty"`
 }
 
 func (s *Service) BeforeCreate(tx *gorm.DB) error {
@@ -30,3 +36,20 @@ func (s *Service) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (s *Service) AfterFind(tx *gorm.DB) error {
+	s.Image = s.LegacyImage
+	if s.ImageMediaID != nil {
+		s.Image, s.ImageThumbnail = mediaURLs(s.ImageMediaID)
+	}
+	return nil
+}
+
+// AllowsDuration reports whether a booking of the given length in minutes
+// fits the service's limits and step.
+func (s *Service) AllowsDuration(minutes int) bool {
+	if minutes < s.MinDuration || minutes > s.MaxDuration {
+		return false
+	}
+	return s.DurationStep <= 0 || (minutes-s.MinDuration)%s.DurationStep == 0
+}
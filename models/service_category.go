@@ -7,16 +7,28 @@ import (
 	"gorm.io/gorm"
 )
 
+// MaxCategoryDepth limits how deeply categories can be nested, e.g.
+// "Cleaning > Deep cleaning > Kitchen" has a depth of 3.
+const MaxCategoryDepth = 5
+
+// ServiceCategory forms a tree through ParentID. Names are unique among
+// siblings (enforced by idx_service_categories_parent_name).
 type ServiceCategory struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Name        string         `gorm:"not null;uniqueIndex" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	Icon        string         `json:"icon,omitempty"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	Services    []Service      `gorm:"foreignKey:CategoryID" json:"services,omitempty"`
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ParentID      *uuid.UUID        `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Name          string            `gorm:"not null" json:"name"`
+	Description   string            `gorm:"type:text" json:"description"`
+	IconMediaID   *uuid.UUID        `gorm:"type:uuid" json:"icon_media_id,omitempty"`
+	LegacyIcon    string            `gorm:"column:icon" json:"-"`    // client-supplied URL from before uploads
+	Icon          string            `gorm:"-" json:"icon,omitempty"` // signed URL, or the legacy URL
+	IconThumbnail string            `gorm:"-" json:"icon_thumbnail,omitempty"`
+	IsActive      bool              `gorm:"default:true" json:"is_active"`
+	SortOrder     int               `gorm:"not null;default:0" json:"sort_order"` // lower comes first
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt    `gorm:"index" json:"-"`
+	Services      []Service         `gorm:"foreignKey:CategoryID" json:"services,omitempty"`
+	Children      []ServiceCategory `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 }
 
 func (sc *ServiceCategory) BeforeCreate(tx *gorm.DB) error {
@@ -25,3 +37,11 @@ func (sc *ServiceCategory) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (sc *ServiceCategory) AfterFind(tx *gorm.DB) error {
+	sc.Icon = sc.LegacyIcon
+	if sc.IconMediaID != nil {
+		sc.Icon, sc.IconThumbnail = mediaURLs(sc.IconMediaID)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ServiceOptionType string
+
+const (
+	OptionSingle   ServiceOptionType = "single"   // pick exactly one choice
+	OptionMulti    ServiceOptionType = "multi"    // pick any number of choices
+	OptionQuantity ServiceOptionType = "quantity" // a count priced per unit
+)
+
+// ServiceOption is a selectable extra on a service, such as an apartment
+// size tier, a pet surcharge or "bring equipment".
+type ServiceOption struct {
+	ID          uuid.UUID             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ServiceID   uuid.UUID             `gorm:"type:uuid;not null;index" json:"service_id"`
+	Name        string                `gorm:"type:varchar(128);not null" json:"name"`
+	Type        ServiceOptionType     `gorm:"type:varchar(16);not null" json:"type"`
+	Required    bool                  `gorm:"not null;default:false" json:"required"`
+	MinQuantity int                   `gorm:"not null;default:0" json:"min_quantity,omitempty"` // quantity options only
+	MaxQuantity int                   `gorm:"not null;default:0" json:"max_quantity,omitempty"`
+	UnitPrice   float64               `gorm:"not null;default:0" json:"unit_price,omitempty"`
+	SortOrder   int                   `gorm:"not null;default:0" json:"sort_order"`
+	Choices     []ServiceOptionChoice `gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE" json:"choices,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+func (o *ServiceOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+type ServiceOptionChoice struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OptionID   uuid.UUID `gorm:"type:uuid;not null;index" json:"option_id"`
+	Label      string    `gorm:"type:varchar(128);not null" json:"label"`
+	PriceDelta float64   `gorm:"not null;default:0" json:"price_delta"` // added to the booking price when chosen
+	SortOrder  int       `gorm:"not null;default:0" json:"sort_order"`
+}
+
+func (c *ServiceOptionChoice) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// BookingOption is the priced snapshot of one option selected on a booking.
+type BookingOption struct {
+	OptionID  uuid.UUID             `json:"option_id"`
+	Name      string                `json:"name"`
+	Type      ServiceOptionType     `json:"type"`
+	Choices   []BookingOptionChoice `json:"choices,omitempty"`
+	Quantity  int                   `json:"quantity,omitempty"`
+	UnitPrice float64               `json:"unit_price,omitempty"`
+	Price     float64               `json:"price"`
+}
+
+type BookingOptionChoice struct {
+	ChoiceID uuid.UUID `json:"choice_id"`
+	Label    string    `json:"label"`
+	Price    float64   `json:"price"`
+}
+
+// BookingOptions is stored as a jsonb array on the booking.
+type BookingOptions []BookingOption
+
+func (o BookingOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(o)
+	return string(raw), err
+}
+
+func (o *BookingOptions) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for BookingOptions column")
+	}
+	return json.Unmarshal(raw, o)
+}
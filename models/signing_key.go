@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is an asymmetric key pair used to sign access tokens. The active
+// key has no ExpiresAt; retired keys stay published until tokens signed with
+// them have expired.
+type SigningKey struct {
+	ID         string     `gorm:"type:varchar(64);primary_key" json:"kid"`
+	Algorithm  string     `gorm:"type:varchar(16);not null" json:"alg"`
+	PublicKey  string     `gorm:"type:text;not null" json:"-"`
+	PrivateKey string     `gorm:"type:text;not null" json:"-"` // encrypted PKCS#8 PEM
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ServiceTranslation holds a service's name and description in one locale.
+// The fields on Service itself are in the default locale and are used
+// whenever a translation is missing.
+type ServiceTranslation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ServiceID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_service_translations_locale" json:"service_id"`
+	Locale      string    `gorm:"type:varchar(8);not null;uniqueIndex:idx_service_translations_locale" json:"locale"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"` // empty falls back to the default locale
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *ServiceTranslation) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// CategoryTranslation is the ServiceTranslation counterpart for categories.
+type CategoryTranslation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CategoryID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_category_translations_locale" json:"category_id"`
+	Locale      string    `gorm:"type:varchar(8);not null;uniqueIndex:idx_category_translations_locale" json:"locale"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *CategoryTranslation) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -13,21 +13,38 @@ const (
 	RoleCustomer UserRole = "customer"
 	RoleWorker   UserRole = "worker"
 	RoleAdmin    UserRole = "admin"
+	RoleStaff    UserRole = "staff" // operations staff, powers come from assigned roles
 )
 
 type User struct {
-	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Email          string         `gorm:"uniqueIndex;not null" json:"email"`
-	Phone          string         `gorm:"uniqueIndex;not null" json:"phone"`
-	PasswordHashed string         `gorm:"not null" json:"-"`
-	Name           string         `gorm:"not null" json:"name"`
-	Role           UserRole       `gorm:"type:varchar(20);not null;default:'customer'" json:"role"`
-	Avatar         string         `json:"avatar,omitempty"`
-	Address        string         `json:"address,omitempty"`
-	IsActive       bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                    uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Email                 string         `gorm:"uniqueIndex;not null" json:"email"`
+	Phone                 string         `gorm:"uniqueIndex:idx_users_phone_present,where:phone <> '';not null" json:"phone"` // empty until added for social logins
+	PasswordHashed        string         `gorm:"not null" json:"-"`
+	Name                  string         `gorm:"not null" json:"name"`
+	Role                  UserRole       `gorm:"type:varchar(20);not null;default:'customer'" json:"role"`
+	AvatarMediaID         *uuid.UUID     `gorm:"type:uuid" json:"avatar_media_id,omitempty"`
+	LegacyAvatar          string         `gorm:"column:avatar" json:"-"`    // client-supplied URL from before uploads
+	Avatar                string         `gorm:"-" json:"avatar,omitempty"` // signed URL, or the legacy URL
+	AvatarThumbnail       string         `gorm:"-" json:"avatar_thumbnail,omitempty"`
+	Address               string         `json:"address,omitempty"`
+	IsActive              bool           `gorm:"default:true" json:"is_active"`
+	PasswordResetRequired bool           `gorm:"not null;default:false" json:"password_reset_required"` // set by admins, cleared by a password reset
+	SessionVersion        int            `gorm:"not null;default:0" json:"-"`                           // bumped to revoke all issued tokens
+	TwoFactorEnabled      bool           `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TwoFactorSecret       string         `gorm:"type:text" json:"-"`          // encrypted, set while enrolling or enabled
+	TwoFactorLastStep     int64          `gorm:"not null;default:0" json:"-"` // last accepted TOTP step, prevents replay
+	EmailVerifiedAt       *time.Time     `json:"email_verified_at,omitempty"`
+	PhoneVerifiedAt       *time.Time     `json:"phone_verified_at,omitempty"`
+	DeletionScheduledFor  *time.Time     `gorm:"index" json:"deletion_scheduled_for,omitempty"` // set while a deletion request is in its grace period
+	ReliabilityScore      float64        `gorm:"not null;default:0;index" json:"-"`             // average rating from workers, 0 until rated; shown to workers and admins only
+	ReliabilityReviews    int            `gorm:"not null;default:0" json:"-"`
+	FlaggedAt             *time.Time     `gorm:"index" json:"-"` // set by admins, shown only in the admin API
+	FlagReason            string         `gorm:"type:text" json:"-"`
+	AnonymizedAt          *time.Time     `json:"-"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
@@ -36,3 +53,17 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (u *User) AfterFind(tx *gorm.DB) error {
+	u.Avatar = u.LegacyAvatar
+	if u.AvatarMediaID != nil {
+		u.Avatar, u.AvatarThumbnail = mediaURLs(u.AvatarMediaID)
+	}
+	return nil
+}
+
+// IsVerified reports whether both the email address and phone number have
+// been confirmed.
+func (u *User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil && u.PhoneVerifiedAt != nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VerificationPurpose string
+
+const (
+	PurposeEmailVerification VerificationPurpose = "email_verification"
+	PurposePhoneVerification VerificationPurpose = "phone_verification"
+	PurposePasswordReset     VerificationPurpose = "password_reset"
+)
+
+// VerificationToken is a hashed one-time secret sent to the user, either as a
+// link token or a short numeric code.
+type VerificationToken struct {
+	ID         uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose    VerificationPurpose `gorm:"type:varchar(32);not null;index" json:"purpose"`
+	Target     string              `gorm:"not null" json:"target"` // email or phone the token was sent to
+	TokenHash  string              `gorm:"type:varchar(64);not null;index" json:"-"`
+	Attempts   int                 `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt  time.Time           `gorm:"not null" json:"expires_at"`
+	ConsumedAt *time.Time          `json:"consumed_at,omitempty"` // used or superseded by a newer token
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+func (v *VerificationToken) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
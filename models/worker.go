@@ -8,23 +8,38 @@ import (
 )
 
 type Worker struct {
-	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID       uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
-	User         User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Bio          string         `gorm:"type:text" json:"bio"`
-	HourlyRate   float64        `gorm:"not null;default:0" json:"hourly_rate"`
-	Rating       float64        `gorm:"default:0" json:"rating"`
-	TotalJobs    int            `gorm:"default:0" json:"total_jobs"`
-	TotalReviews int            `gorm:"default:0" json:"total_reviews"`
-	IsVerified   bool           `gorm:"default:false" json:"is_verified"`
-	IsAvailable  bool           `gorm:"default:true" json:"is_available"`
-	ServiceAreas string         `gorm:"type:text" json:"service_areas"` // JSON array of areas
-	WorkingHours string         `gorm:"type:text" json:"working_hours"` // JSON schedule
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID             uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	User               User               `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Bio                string             `gorm:"type:text" json:"bio"`
+	HourlyRate         float64            `gorm:"not null;default:0;index" json:"hourly_rate"`
+	Rating             float64            `gorm:"default:0;index" json:"rating"`
+	TotalJobs          int                `gorm:"default:0;index" json:"total_jobs"`
+	TotalReviews       int                `gorm:"default:0" json:"total_reviews"`
+	IsVerified         bool               `gorm:"default:false" json:"is_verified"`                                                // approved and not expired
+	VerificationStatus VerificationStatus `gorm:"type:varchar(20);not null;default:'unverified';index" json:"verification_status"` // latest verification
+	VerifiedUntil      *time.Time         `json:"verified_until,omitempty"`
+	IsAvailable        bool               `gorm:"default:true" json:"is_available"`
+	ServiceAreas       string             `gorm:"type:text" json:"service_areas"` // JSON array of areas
+	WorkingHours       string             `gorm:"type:text" json:"working_hours"` // JSON schedule
+	Latitude           *float64           `gorm:"index:idx_workers_location,priority:1" json:"-"`
+	Longitude          *float64           `gorm:"index:idx_workers_location,priority:2" json:"-"`
+	ServiceRadiusKm    float64            `gorm:"not null;default:10" json:"service_radius_km"` // how far from their location the worker travels
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt     `gorm:"index" json:"-"`
+
+	Services          []Service                `gorm:"many2many:worker_services" json:"services,omitempty"`
+	Languages         []WorkerLanguage         `gorm:"foreignKey:WorkerID;constraint:OnDelete:CASCADE" json:"languages,omitempty"`
+	AvailabilitySlots []WorkerAvailabilitySlot `gorm:"foreignKey:WorkerID;constraint:OnDelete:CASCADE" json:"availability_slots,omitempty"`
+
+	// Set only by searches near a location, rounded before it is shown
+	DistanceKm *float64 `gorm:"->;-:migration;column:distance_km" json:"distance_km,omitempty"`
 }
 
+// MaxServiceRadiusKm caps both a worker's travel radius and search distance.
+const MaxServiceRadiusKm = 50
+
 func (w *Worker) BeforeCreate(tx *gorm.DB) error {
 	if w.ID == uuid.Nil {
 		w.ID = uuid.New()
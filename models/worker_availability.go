@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkerLanguage is a language a worker speaks, as an ISO 639-1 code.
+type WorkerLanguage struct {
+	WorkerID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Language string    `gorm:"type:varchar(8);primaryKey;index" json:"language"`
+}
+
+// WorkerAvailabilitySlot is a recurring weekly window in which a worker takes
+// jobs. Times are minutes from midnight in APP_TIMEZONE; a slot never
+// crosses midnight.
+type WorkerAvailabilitySlot struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkerID    uuid.UUID `gorm:"type:uuid;not null;index:idx_worker_slots_lookup,priority:1" json:"-"`
+	Weekday     int       `gorm:"not null;index:idx_worker_slots_lookup,priority:2;check:weekday BETWEEN 0 AND 6" json:"weekday"` // 0 is Sunday
+	StartMinute int       `gorm:"not null;check:start_minute >= 0 AND start_minute < end_minute" json:"start_minute"`
+	EndMinute   int       `gorm:"not null;check:end_minute <= 1440" json:"end_minute"`
+}
+
+func (s *WorkerAvailabilitySlot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WorkerPreferenceKind string
+
+const (
+	PreferenceFavorite WorkerPreferenceKind = "favorite"
+	PreferenceBlocked  WorkerPreferenceKind = "blocked"
+)
+
+// WorkerPreference records that a customer favors or has blocked a worker.
+// A pair has at most one preference, so blocking replaces a favorite.
+type WorkerPreference struct {
+	CustomerID uuid.UUID            `gorm:"type:uuid;primaryKey" json:"customer_id"`
+	WorkerID   uuid.UUID            `gorm:"type:uuid;primaryKey;index" json:"worker_id"`
+	Worker     *Worker              `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
+	Kind       WorkerPreferenceKind `gorm:"type:varchar(16);not null" json:"kind"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VerificationStatus string
+
+const (
+	VerificationUnverified    VerificationStatus = "unverified"
+	VerificationPending       VerificationStatus = "pending"
+	VerificationInfoRequested VerificationStatus = "info_requested"
+	VerificationApproved      VerificationStatus = "approved"
+	VerificationRejected      VerificationStatus = "rejected"
+	VerificationExpired       VerificationStatus = "expired"
+)
+
+type DocumentType string
+
+const (
+	DocumentIDCard        DocumentType = "id_card"
+	DocumentPassport      DocumentType = "passport"
+	DocumentDriverLicense DocumentType = "driver_license"
+	DocumentCertificate   DocumentType = "certificate"
+	DocumentOther         DocumentType = "other"
+)
+
+// IsIdentity reports whether the document proves who the worker is. Every
+// verification needs at least one.
+func (t DocumentType) IsIdentity() bool {
+	return t == DocumentIDCard || t == DocumentPassport || t == DocumentDriverLicense
+}
+
+// WorkerDocument is a file uploaded for verification. It belongs to no
+// verification until the worker submits, and cannot change afterwards.
+type WorkerDocument struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkerID       uuid.UUID    `gorm:"type:uuid;not null;index" json:"worker_id"`
+	VerificationID *uuid.UUID   `gorm:"type:uuid;index" json:"verification_id,omitempty"`
+	Type           DocumentType `gorm:"type:varchar(32);not null" json:"type"`
+	FileName       string       `gorm:"not null" json:"file_name"`
+	ContentType    string       `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size           int64        `gorm:"not null" json:"size"`
+	SHA256         string       `gorm:"type:char(64);not null" json:"sha256"`
+	StorageKey     string       `gorm:"not null" json:"-"`
+	ExpiresOn      *time.Time   `gorm:"type:date" json:"expires_on,omitempty"` // printed expiry of the document itself
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+func (d *WorkerDocument) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// WorkerVerification is one review of a worker's documents, from
+// submission to a decision. Renewals open a new verification.
+type WorkerVerification struct {
+	ID          uuid.UUID                 `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkerID    uuid.UUID                 `gorm:"type:uuid;not null;index" json:"worker_id"`
+	Worker      *Worker                   `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
+	Status      VerificationStatus        `gorm:"type:varchar(20);not null;index" json:"status"`
+	SubmittedAt time.Time                 `gorm:"not null" json:"submitted_at"` // last (re)submission
+	ReviewerID  *uuid.UUID                `gorm:"type:uuid" json:"reviewer_id,omitempty"`
+	ReviewedAt  *time.Time                `json:"reviewed_at,omitempty"`
+	Reason      string                    `gorm:"type:text" json:"reason,omitempty"` // shown to the worker
+	ExpiresAt   *time.Time                `json:"expires_at,omitempty"`              // set on approval
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	Documents   []WorkerDocument          `gorm:"foreignKey:VerificationID" json:"documents,omitempty"`
+	Events      []WorkerVerificationEvent `gorm:"foreignKey:VerificationID;constraint:OnDelete:CASCADE" json:"events,omitempty"`
+}
+
+func (v *WorkerVerification) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsOpen reports whether the verification still awaits a decision.
+func (v *WorkerVerification) IsOpen() bool {
+	return v.Status == VerificationPending || v.Status == VerificationInfoRequested
+}
+
+// WorkerVerificationEvent records each status change for the history shown
+// to the worker and to reviewers.
+type WorkerVerificationEvent struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VerificationID uuid.UUID          `gorm:"type:uuid;not null;index" json:"verification_id"`
+	Status         VerificationStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Reason         string             `gorm:"type:text" json:"reason,omitempty"`
+	ActorID        *uuid.UUID         `gorm:"type:uuid" json:"actor_id,omitempty"` // nil for automatic changes
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+func (e *WorkerVerificationEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
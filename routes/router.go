@@ -10,13 +10,15 @@ import (
 
 func SetupRouter() *gin.Engine {
 	r := gin.Default()
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Locale())
 
 	// CORS configuration
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept-Language", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Language", middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
@@ -25,6 +27,9 @@ func SetupRouter() *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public token signing keys
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
@@ -33,15 +38,27 @@ func SetupRouter() *gin.Engine {
 		{
 			auth.POST("/register", controllers.Register)
 			auth.POST("/login", controllers.Login)
+			auth.POST("/2fa", controllers.VerifyTwoFactorLogin)
+			auth.GET("/verify-email", controllers.VerifyEmail)
+			auth.POST("/verify-email", controllers.VerifyEmail)
+			auth.POST("/forgot-password", controllers.ForgotPassword)
+			auth.POST("/reset-password", controllers.ResetPassword)
+			auth.GET("/oidc/:provider", controllers.StartOIDCLogin)
+			auth.GET("/oidc/:provider/callback", controllers.OIDCCallback)
 		}
 
 		// Public service/category routes
 		v1.GET("/services", controllers.GetServices)
 		v1.GET("/services/:id", controllers.GetServiceByID)
 		v1.GET("/categories", controllers.GetCategories)
+		v1.GET("/categories/tree", controllers.GetCategoryTree)
+		v1.GET("/categories/:id", controllers.GetCategoryByID)
+		v1.GET("/search", controllers.Search)
+		v1.GET("/search/suggest", controllers.SearchSuggest)
 		v1.GET("/workers", controllers.GetWorkers)
 		v1.GET("/workers/:id", controllers.GetWorkerByID)
 		v1.GET("/workers/:id/reviews", controllers.GetWorkerReviews)
+		v1.GET("/media/:id", controllers.ServeMedia)
 
 		// Protected routes
 		protected := v1.Group("")
@@ -51,17 +68,55 @@ func SetupRouter() *gin.Engine {
 			users := protected.Group("/users")
 			{
 				users.GET("/profile", controllers.GetProfile)
-				users.PUT("/profile", controllers.UpdateProfile)
-				users.PUT("/password", controllers.ChangePassword)
+				users.PUT("/profile", middleware.BlockImpersonation(), controllers.UpdateProfile)
+				users.PUT("/me/avatar", middleware.BlockImpersonation(), controllers.UploadAvatar)
+				users.DELETE("/me/avatar", middleware.BlockImpersonation(), controllers.DeleteAvatar)
+				users.PUT("/password", middleware.BlockImpersonation(), controllers.ChangePassword)
+				users.POST("/verify-email/resend", middleware.BlockImpersonation(), controllers.ResendEmailVerification)
+				users.POST("/verify-phone/send", middleware.BlockImpersonation(), controllers.SendPhoneVerification)
+				users.POST("/verify-phone", middleware.BlockImpersonation(), controllers.VerifyPhone)
+
+				twoFactor := users.Group("/2fa")
+				twoFactor.Use(middleware.BlockImpersonation())
+				{
+					twoFactor.POST("/enroll", controllers.EnrollTwoFactor)
+					twoFactor.POST("/confirm", controllers.ConfirmTwoFactor)
+					twoFactor.POST("/disable", controllers.DisableTwoFactor)
+					twoFactor.POST("/recovery-codes", controllers.RegenerateRecoveryCodes)
+				}
+
+				users.GET("/identities", controllers.GetIdentities)
+				users.DELETE("/identities/:id", middleware.BlockImpersonation(), controllers.UnlinkIdentity)
+				users.GET("/me/export", middleware.BlockImpersonation(), controllers.ExportMyData)
+				users.DELETE("/me", middleware.BlockImpersonation(), controllers.RequestAccountDeletion)
+				users.POST("/me/restore", middleware.BlockImpersonation(), controllers.RestoreAccount)
+				users.GET("/me/addresses", controllers.GetAddresses)
+				users.POST("/me/addresses", controllers.CreateAddress)
+				users.PUT("/me/addresses/:id", controllers.UpdateAddress)
+				users.DELETE("/me/addresses/:id", controllers.DeleteAddress)
+				users.POST("/me/addresses/:id/default", controllers.SetDefaultAddress)
+				users.GET("/me/favorite-workers", controllers.GetFavoriteWorkers)
+				users.PUT("/me/favorite-workers/:workerId", controllers.AddFavoriteWorker)
+				users.DELETE("/me/favorite-workers/:workerId", controllers.RemoveFavoriteWorker)
+				users.GET("/me/blocked-workers", controllers.GetBlockedWorkers)
+				users.PUT("/me/blocked-workers/:workerId", controllers.BlockWorker)
+				users.DELETE("/me/blocked-workers/:workerId", controllers.UnblockWorker)
 			}
 
+			// Lets an impersonation token end its own session
+			protected.POST("/impersonation/end", controllers.EndCurrentImpersonation)
+
 			// Booking routes (all authenticated users)
 			bookings := protected.Group("/bookings")
 			{
-				bookings.POST("", controllers.CreateBooking)
+				bookings.POST("", middleware.RequireVerifiedAccount(), controllers.CreateBooking)
 				bookings.GET("", controllers.GetBookings)
 				bookings.GET("/:id", controllers.GetBookingByID)
 				bookings.PUT("/:id/cancel", controllers.CancelBooking)
+				bookings.GET("/:id/timeline", controllers.GetBookingTimeline)
+				bookings.GET("/:id/photos", controllers.GetBookingPhotos)
+				bookings.POST("/:id/photos", controllers.UploadBookingPhoto)
+				bookings.DELETE("/:id/photos/:photoId", controllers.DeleteBookingPhoto)
 			}
 
 			// Worker-only routes
@@ -71,24 +126,133 @@ func SetupRouter() *gin.Engine {
 				worker.GET("/profile", controllers.GetWorkerProfile)
 				worker.PUT("/profile", controllers.UpdateWorkerProfile)
 				worker.PUT("/availability", controllers.SetAvailability)
+				worker.PUT("/services", controllers.SetWorkerServices)
+				worker.PUT("/languages", controllers.SetWorkerLanguages)
+				worker.PUT("/schedule", controllers.SetWorkerSchedule)
+				worker.GET("/verification", controllers.GetMyVerification)
+				worker.POST("/verification/documents", middleware.BlockImpersonation(), controllers.UploadVerificationDocument)
+				worker.GET("/verification/documents/:id/file", controllers.DownloadMyVerificationDocument)
+				worker.DELETE("/verification/documents/:id", middleware.BlockImpersonation(), controllers.DeleteVerificationDocument)
+				worker.POST("/verification/submit", middleware.BlockImpersonation(), controllers.SubmitVerification)
 				worker.GET("/pending-bookings", controllers.GetPendingBookings)
-				worker.PUT("/bookings/:id/accept", controllers.AcceptBooking)
+				worker.PUT("/bookings/:id/accept", middleware.RequireVerifiedAccount(), controllers.AcceptBooking)
+				worker.PUT("/bookings/:id/decline", controllers.DeclineBooking)
 				worker.PUT("/bookings/:id/start", controllers.StartBooking)
 				worker.PUT("/bookings/:id/complete", controllers.CompleteBooking)
+				worker.POST("/customer-reviews", controllers.CreateCustomerReview)
+				worker.PUT("/reviews/:id/reply", controllers.ReplyToReview)
 			}
 
 			// Customer reviews
 			reviews := protected.Group("/reviews")
 			{
 				reviews.POST("", controllers.CreateReview)
+				reviews.POST("/:id/report", controllers.ReportReview)
 			}
 
 			// Admin routes
 			admin := protected.Group("/admin")
-			admin.Use(middleware.RoleMiddleware(string(models.RoleAdmin)))
+			admin.Use(middleware.RoleMiddleware(string(models.RoleAdmin), string(models.RoleStaff)), middleware.RequireTwoFactor())
 			{
-				admin.POST("/services", controllers.CreateService)
-				admin.POST("/categories", controllers.CreateCategory)
+				services := admin.Group("/services")
+				services.Use(middleware.RequirePermission(models.PermServicesManage))
+				{
+					services.GET("", controllers.AdminListServices)
+					services.POST("", controllers.CreateService)
+					services.PUT("/order", controllers.ReorderServices)
+					services.PUT("/:id", controllers.UpdateService)
+					services.POST("/:id/activate", controllers.ActivateService)
+					services.POST("/:id/deactivate", controllers.DeactivateService)
+					services.DELETE("/:id", controllers.DeleteService)
+					services.POST("/:id/restore", controllers.RestoreService)
+					services.POST("/:id/options", controllers.CreateServiceOption)
+					services.PUT("/:id/options/:optionId", controllers.UpdateServiceOption)
+					services.DELETE("/:id/options/:optionId", controllers.DeleteServiceOption)
+					services.GET("/:id/translations", controllers.GetServiceTranslations)
+					services.PUT("/:id/translations/:locale", controllers.PutServiceTranslation)
+					services.DELETE("/:id/translations/:locale", controllers.DeleteServiceTranslation)
+					services.PUT("/:id/image", controllers.UploadServiceImage)
+					services.DELETE("/:id/image", controllers.DeleteServiceImage)
+				}
+
+				categories := admin.Group("/categories")
+				categories.Use(middleware.RequirePermission(models.PermCategoriesManage))
+				{
+					categories.GET("", controllers.AdminListCategories)
+					categories.POST("", controllers.CreateCategory)
+					categories.PUT("/order", controllers.ReorderCategories)
+					categories.PUT("/:id", controllers.UpdateCategory)
+					categories.PUT("/:id/parent", controllers.MoveCategory)
+					categories.POST("/:id/activate", controllers.ActivateCategory)
+					categories.POST("/:id/deactivate", controllers.DeactivateCategory)
+					categories.DELETE("/:id", controllers.DeleteCategory)
+					categories.POST("/:id/restore", controllers.RestoreCategory)
+					categories.GET("/:id/translations", controllers.GetCategoryTranslations)
+					categories.PUT("/:id/translations/:locale", controllers.PutCategoryTranslation)
+					categories.DELETE("/:id/translations/:locale", controllers.DeleteCategoryTranslation)
+					categories.PUT("/:id/icon", controllers.UploadCategoryIcon)
+					categories.DELETE("/:id/icon", controllers.DeleteCategoryIcon)
+				}
+
+				impersonation := admin.Group("")
+				impersonation.Use(middleware.RequirePermission(models.PermUsersImpersonate))
+				{
+					impersonation.POST("/users/:id/impersonate", controllers.StartImpersonation)
+					impersonation.GET("/impersonations", controllers.GetImpersonations)
+					impersonation.POST("/impersonations/:id/end", controllers.EndImpersonation)
+				}
+
+				admin.GET("/users", middleware.RequirePermission(models.PermUsersRead), controllers.AdminListUsers)
+				admin.GET("/users/:id", middleware.RequirePermission(models.PermUsersRead), controllers.AdminGetUser)
+				admin.GET("/users/:id/customer-reviews", middleware.RequirePermission(models.PermUsersRead), controllers.AdminGetCustomerReviews)
+
+				userAdmin := admin.Group("/users/:id")
+				userAdmin.Use(middleware.RequirePermission(models.PermUsersManage))
+				{
+					userAdmin.POST("/deactivate", controllers.AdminDeactivateUser)
+					userAdmin.POST("/reactivate", controllers.AdminReactivateUser)
+					userAdmin.POST("/force-password-reset", controllers.AdminForcePasswordReset)
+					userAdmin.PUT("/role", controllers.AdminChangeUserRole)
+					userAdmin.POST("/restore", controllers.AdminRestoreUser)
+					userAdmin.POST("/flag", controllers.AdminFlagUser)
+					userAdmin.POST("/unflag", controllers.AdminUnflagUser)
+				}
+
+				verifications := admin.Group("/verifications")
+				verifications.Use(middleware.RequirePermission(models.PermWorkersVerify))
+				{
+					verifications.GET("", controllers.AdminListVerifications)
+					verifications.GET("/:id", controllers.AdminGetVerification)
+					verifications.GET("/:id/documents/:documentId/file", controllers.AdminDownloadVerificationDocument)
+					verifications.POST("/:id/approve", controllers.ApproveVerification)
+					verifications.POST("/:id/reject", controllers.RejectVerification)
+					verifications.POST("/:id/request-info", controllers.RequestVerificationInfo)
+				}
+
+				moderation := admin.Group("/reviews")
+				moderation.Use(middleware.RequirePermission(models.PermReviewsModerate))
+				{
+					moderation.GET("", controllers.AdminListReviews)
+					moderation.GET("/:id", controllers.AdminGetReview)
+					moderation.POST("/:id/hide", controllers.HideReview)
+					moderation.POST("/:id/restore", controllers.RestoreReview)
+					moderation.POST("/:id/dismiss-reports", controllers.DismissReviewReports)
+				}
+
+				admin.GET("/audit-log", middleware.RequirePermission(models.PermAuditRead), controllers.GetAuditLog)
+
+				roles := admin.Group("")
+				roles.Use(middleware.RequirePermission(models.PermRolesManage))
+				{
+					roles.GET("/permissions", controllers.GetPermissions)
+					roles.GET("/roles", controllers.GetRoles)
+					roles.POST("/roles", controllers.CreateRole)
+					roles.PUT("/roles/:id", controllers.UpdateRole)
+					roles.DELETE("/roles/:id", controllers.DeleteRole)
+					roles.GET("/users/:id/roles", controllers.GetUserRoles)
+					roles.POST("/users/:id/roles", controllers.AssignRole)
+					roles.DELETE("/users/:id/roles/:assignmentId", controllers.RevokeRole)
+				}
 			}
 		}
 	}
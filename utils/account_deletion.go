@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"gorm.io/gorm"
+)
+
+// AnonymizeDeletedAccounts scrubs personal data from accounts whose deletion
+// grace period has ended. Cancelling the request or an admin restore clears
+// deletion_scheduled_for, so those accounts are left alone; a plain soft
+// delete stays restorable. Bookings, prices and reviews are kept for
+// accounting; they just no longer point at identifiable data. Audit log
+// entries are left to the audit retention policy.
+func AnonymizeDeletedAccounts() error {
+	var users []models.User
+	if err := config.DB.Unscoped().
+		Where("anonymized_at IS NULL").
+		Where("deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= ?", time.Now()).
+		Limit(100).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := anonymizeUser(user); err != nil {
+			return fmt.Errorf("anonymize user %s: %w", user.ID, err)
+		}
+		log.Printf("Anonymized deleted account %s", user.ID)
+	}
+	return nil
+}
+
+func anonymizeUser(user models.User) error {
+	now := time.Now()
+
+	var documentKeys []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"email":                  fmt.Sprintf("deleted-%s@deleted.invalid", user.ID),
+			"phone":                  "",
+			"password_hashed":        "",
+			"name":                   "Deleted user",
+			"avatar":                 "",
+			"avatar_media_id":        nil,
+			"address":                "",
+			"is_active":              false,
+			"session_version":        gorm.Expr("session_version + 1"),
+			"two_factor_enabled":     false,
+			"two_factor_secret":      "",
+			"email_verified_at":      nil,
+			"phone_verified_at":      nil,
+			"deletion_scheduled_for": nil,
+			"flag_reason":            "",
+			"anonymized_at":          now,
+			"deleted_at":             gorm.Expr("COALESCE(deleted_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+
+		// Booking addresses and notes identify the customer's home; the city
+		// and district are kept for regional reporting
+		if err := tx.Unscoped().Model(&models.Booking{}).Where("customer_id = ?", user.ID).Updates(map[string]interface{}{
+			"address":              "",
+			"notes":                "",
+			"address_street":       "",
+			"address_ward":         "",
+			"address_postal_code":  "",
+			"address_latitude":     nil,
+			"address_longitude":    nil,
+			"address_access_notes": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Worker{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+			"bio":           "",
+			"service_areas": "",
+			"working_hours": "",
+			"is_available":  false,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Address{}).Error; err != nil {
+			return err
+		}
+
+		// Identity documents are removed outright; the review history stays
+		workerIDs := tx.Unscoped().Model(&models.Worker{}).Select("id").Where("user_id = ?", user.ID)
+		if err := tx.Model(&models.WorkerDocument{}).Where("worker_id IN (?)", workerIDs).
+			Pluck("storage_key", &documentKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("worker_id IN (?)", workerIDs).Delete(&models.WorkerDocument{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.VerificationToken{},
+			&models.RecoveryCode{},
+			&models.UserIdentity{},
+			&models.UserRoleAssignment{},
+		} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.AvatarMediaID != nil {
+		if err := ReleaseMedia(*user.AvatarMediaID); err != nil {
+			log.Printf("Failed to release avatar of %s: %v", user.ID, err)
+		}
+	}
+	for _, key := range documentKeys {
+		if err := Blobs.Delete(key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+	return nil
+}
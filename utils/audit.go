@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fields that change on every write and only add noise to diffs
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// RecordAudit appends an entry to the audit log, taking the actor and client
+// details from the request. Failures are logged and never block the request.
+func RecordAudit(c *gin.Context, action, resourceType, resourceID string, metadata map[string]interface{}) {
+	entry := newAuditEntry(c, action, resourceType, resourceID)
+
+	if metadata != nil {
+		if raw, err := json.Marshal(metadata); err == nil {
+			entry.Metadata = raw
+		}
+	}
+
+	saveAuditEntry(entry)
+}
+
+// AuditChange records a write along with the fields that differ between the
+// before and after states. Pass nil as before for creations and as after
+// for deletions. Nested objects (preloaded relations) are left out.
+func AuditChange(c *gin.Context, action, resourceType, resourceID string, before, after interface{}) {
+	AuditChangeWithMetadata(c, action, resourceType, resourceID, before, after, nil)
+}
+
+// AuditChangeWithMetadata is AuditChange with extra context, such as the
+// reason an admin gave for the change.
+func AuditChangeWithMetadata(c *gin.Context, action, resourceType, resourceID string, before, after interface{}, metadata map[string]interface{}) {
+	entry := newAuditEntry(c, action, resourceType, resourceID)
+
+	if metadata != nil {
+		if raw, err := json.Marshal(metadata); err == nil {
+			entry.Metadata = raw
+		}
+	}
+
+	oldFields, newFields := auditFields(before), auditFields(after)
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for key, value := range newFields {
+		if old, ok := oldFields[key]; !ok || !reflect.DeepEqual(old, value) {
+			changedAfter[key] = value
+			if ok {
+				changedBefore[key] = old
+			}
+		}
+	}
+	for key, old := range oldFields {
+		if _, ok := newFields[key]; !ok {
+			changedBefore[key] = old
+		}
+	}
+
+	if len(changedBefore) > 0 {
+		entry.Before, _ = json.Marshal(changedBefore)
+	}
+	if len(changedAfter) > 0 {
+		entry.After, _ = json.Marshal(changedAfter)
+	}
+
+	saveAuditEntry(entry)
+}
+
+// PurgeAuditLog removes entries older than AUDIT_RETENTION_DAYS.
+func PurgeAuditLog() error {
+	days := config.AppConfig.AuditRetentionDays
+	if days == 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lets the append-only trigger allow this transaction's deletes
+		if err := tx.Exec("SET LOCAL goodstuff.audit_purge = 'on'").Error; err != nil {
+			return err
+		}
+		result := tx.Where("created_at < ?", cutoff).Delete(&models.AuditLog{})
+		if result.Error == nil && result.RowsAffected > 0 {
+			log.Printf("Purged %d audit log entries older than %d days", result.RowsAffected, days)
+		}
+		return result.Error
+	})
+}
+
+func newAuditEntry(c *gin.Context, action, resourceType, resourceID string) models.AuditLog {
+	entry := models.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RequestID:    c.GetString("requestID"),
+		Method:       c.Request.Method,
+		Path:         c.Request.URL.Path,
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	}
+
+	if userID, ok := c.Get("userID"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			entry.ActorID = &id
+		}
+	}
+
+	if impersonatorID, ok := c.Get("impersonatorID"); ok {
+		if id, ok := impersonatorID.(uuid.UUID); ok {
+			entry.ImpersonatorID = &id
+		}
+	}
+
+	return entry
+}
+
+func saveAuditEntry(entry models.AuditLog) {
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Failed to record audit event %s: %v", entry.Action, err)
+	}
+}
+
+// auditFields flattens a model to its JSON fields. Fields hidden from JSON,
+// such as password hashes, never reach the audit log.
+func auditFields(v interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if v == nil {
+		return fields
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	var decoded map[string]interface{}
+	if json.Unmarshal(raw, &decoded) != nil {
+		return fields
+	}
+
+	for key, value := range decoded {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if _, nested := value.(map[string]interface{}); nested {
+			continue
+		}
+		if list, ok := value.([]interface{}); ok && len(list) > 0 {
+			if _, nested := list[0].(map[string]interface{}); nested {
+				continue
+			}
+		}
+		fields[key] = value
+	}
+	return fields
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParamError is a rejected query parameter. Message is a fixed catalog key
+// so it can be translated; Param names the parameter at fault.
+type ParamError struct {
+	Message string
+	Param   string
+}
+
+func (e *ParamError) Error() string {
+	return e.Message
+}
+
+// ParseTimeRange reads an optional half-open [from, to) range given in
+// RFC 3339. Returned errors are *ParamError.
+func ParseTimeRange(c *gin.Context, fromParam, toParam string) (from, to *time.Time, err error) {
+	parse := func(param string) (*time.Time, error) {
+		raw := c.Query(param)
+		if raw == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, &ParamError{Message: "Invalid time, use RFC 3339", Param: param}
+		}
+		return &t, nil
+	}
+
+	if from, err = parse(fromParam); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parse(toParam); err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, &ParamError{Message: "Invalid time range, start must be before end", Param: fromParam}
+	}
+	return from, to, nil
+}
+
+// ParseSet reads a comma-separated query parameter such as
+// ?status=pending,confirmed and checks every value against allowed.
+// Returned errors are *ParamError.
+func ParseSet(c *gin.Context, param string, allowed []string) ([]string, error) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		value = strings.TrimSpace(value)
+		if !slices.Contains(allowed, value) {
+			return nil, &ParamError{Message: "Invalid filter value", Param: param}
+		}
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
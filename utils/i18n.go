@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/gin-gonic/gin"
+)
+
+// messageCatalogs maps the English messages used throughout the handlers to
+// their translations. Messages without an entry are returned unchanged.
+var messageCatalogs = map[string]map[string]string{
+	"vi": viMessages,
+}
+
+// NegotiateLocale picks the response locale from an explicit lang parameter,
+// then the Accept-Language header, then DEFAULT_LOCALE. Region subtags are
+// ignored, so "vi-VN" selects "vi".
+func NegotiateLocale(lang, acceptLanguage string) string {
+	if locale := baseLanguage(lang); config.IsSupportedLocale(locale) {
+		return locale
+	}
+
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if locale := baseLanguage(fields[0]); q > 0 && config.IsSupportedLocale(locale) {
+			candidates = append(candidates, candidate{locale, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) > 0 {
+		return candidates[0].locale
+	}
+
+	return config.AppConfig.DefaultLocale
+}
+
+// Locale returns the locale negotiated for the request by middleware.Locale.
+func Locale(c *gin.Context) string {
+	if locale := c.GetString("locale"); locale != "" {
+		return locale
+	}
+	return config.AppConfig.DefaultLocale
+}
+
+// Translate returns message in the given locale, falling back to English.
+func Translate(locale, message string) string {
+	if translated, ok := messageCatalogs[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+func baseLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
@@ -5,34 +5,104 @@ import (
 	"time"
 
 	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const (
+	TokenUseSession            = "session"
+	TokenUseTwoFactorChallenge = "2fa_challenge"
+	TokenUseImpersonation      = "impersonation"
+)
+
+const twoFactorChallengeTTL = 5 * time.Minute
+
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID         uuid.UUID `json:"user_id"`
+	Role           string    `json:"role"`
+	SessionVersion int       `json:"ver"`
+	TokenUse       string    `json:"use"`
+	// Actor identifies the admin behind an impersonation token (RFC 8693)
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, role string) (string, error) {
-	claims := Claims{
-		UserID: userID,
-		Role:   role,
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+func GenerateToken(user models.User) (string, error) {
+	return signToken(user, TokenUseSession, time.Duration(config.AppConfig.JWTExpiryHours)*time.Hour)
+}
+
+// GenerateTwoFactorChallenge issues a short-lived token that only proves the
+// password step succeeded; it must be exchanged for a session with a code.
+func GenerateTwoFactorChallenge(user models.User) (string, error) {
+	return signToken(user, TokenUseTwoFactorChallenge, twoFactorChallengeTTL)
+}
+
+// GenerateImpersonationToken lets impersonator act as user until expiresAt.
+// The session ID becomes the token's jti.
+func GenerateImpersonationToken(user models.User, impersonatorID, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	claims := newClaims(user, TokenUseImpersonation, time.Until(expiresAt))
+	claims.Actor = &ActorClaim{Subject: impersonatorID.String()}
+	claims.ID = sessionID.String()
+	return signClaims(claims)
+}
+
+func signToken(user models.User, use string, ttl time.Duration) (string, error) {
+	return signClaims(newClaims(user, use, ttl))
+}
+
+func newClaims(user models.User, use string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		UserID:         user.ID,
+		Role:           string(user.Role),
+		SessionVersion: user.SessionVersion,
+		TokenUse:       use,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.AppConfig.JWTExpiryHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    config.AppConfig.JWTIssuer,
+			Subject:   user.ID.String(),
+			Audience:  jwt.ClaimStrings{config.AppConfig.JWTAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
 		},
 	}
+}
+
+func signClaims(claims Claims) (string, error) {
+	key, err := activeSigningKey()
+	if err != nil {
+		return "", err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.AppConfig.JWTSecret))
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.AppConfig.JWTSecret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, err := verificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// The kid must also agree with the algorithm it was issued for
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("unexpected signing algorithm")
+		}
+		return key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+		jwt.WithIssuer(config.AppConfig.JWTIssuer),
+		jwt.WithAudience(config.AppConfig.JWTAudience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Arbitrary constant used with pg_advisory_xact_lock so that only one
+// instance rotates keys at a time.
+const keyRotationLockID = 727361
+
+const keyReloadCooldown = 10 * time.Second
+
+type signingKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+var keyRing struct {
+	sync.RWMutex
+	active     *signingKey
+	keys       map[string]*signingKey
+	lastReload time.Time
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKey decodes an RSA, EC or Ed25519 JWK into a Go public key.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// InitSigningKeys makes sure an active key for the configured algorithm exists
+// and loads all published keys into memory.
+func InitSigningKeys() error {
+	return RotateSigningKeys()
+}
+
+// RotateSigningKeys creates a new active key when the current one is older
+// than JWT_KEY_ROTATION_HOURS (or uses a different algorithm), retires the
+// previous keys and drops keys whose tokens can no longer be valid.
+func RotateSigningKeys() error {
+	cfg := config.AppConfig
+	now := time.Now()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", keyRotationLockID).Error; err != nil {
+			return err
+		}
+
+		var active models.SigningKey
+		err := tx.Where("expires_at IS NULL").Order("created_at DESC").First(&active).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		rotationDue := err != nil ||
+			active.Algorithm != cfg.JWTAlgorithm ||
+			now.Sub(active.CreatedAt) >= time.Duration(cfg.JWTKeyRotationHours)*time.Hour
+
+		if rotationDue {
+			key, err := generateSigningKey(cfg.JWTAlgorithm)
+			if err != nil {
+				return err
+			}
+
+			// Tokens signed by the old keys stay verifiable until they expire
+			retireAt := now.Add(time.Duration(cfg.JWTExpiryHours) * time.Hour)
+			if err := tx.Model(&models.SigningKey{}).
+				Where("expires_at IS NULL").
+				Update("expires_at", retireAt).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Create(key).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("expires_at < ?", now).Delete(&models.SigningKey{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return loadSigningKeys()
+}
+
+func loadSigningKeys() error {
+	var rows []models.SigningKey
+	if err := config.DB.Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+
+	keys := make(map[string]*signingKey, len(rows))
+	var active *signingKey
+	for _, row := range rows {
+		key, err := decodeSigningKey(row)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", row.ID, err)
+		}
+		keys[key.ID] = key
+		if active == nil && row.ExpiresAt == nil {
+			active = key
+		}
+	}
+
+	if active == nil {
+		return errors.New("no active signing key")
+	}
+
+	keyRing.Lock()
+	keyRing.active = active
+	keyRing.keys = keys
+	keyRing.lastReload = time.Now()
+	keyRing.Unlock()
+	return nil
+}
+
+func activeSigningKey() (*signingKey, error) {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+
+	if keyRing.active == nil {
+		return nil, errors.New("signing keys not initialized")
+	}
+	return keyRing.active, nil
+}
+
+// verificationKey looks up a published key by kid. Unknown kids trigger a
+// reload (rate limited) since another instance may have just rotated.
+func verificationKey(kid string) (*signingKey, error) {
+	keyRing.RLock()
+	key, ok := keyRing.keys[kid]
+	stale := time.Since(keyRing.lastReload) > keyReloadCooldown
+	keyRing.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if stale {
+		if err := loadSigningKeys(); err != nil {
+			return nil, err
+		}
+		keyRing.RLock()
+		key, ok = keyRing.keys[kid]
+		keyRing.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("unknown signing key")
+}
+
+// PublicJWKS returns every key that may still have valid tokens outstanding.
+func PublicJWKS() JWKSet {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keyRing.keys {
+		jwk := JWK{
+			Use: "sig",
+			Alg: key.Algorithm,
+			Kid: key.ID,
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func generateSigningKey(algorithm string) (*models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := EncryptString(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		ID:         uuid.NewString(),
+		Algorithm:  algorithm,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		PrivateKey: encrypted,
+	}, nil
+}
+
+func decodeSigningKey(row models.SigningKey) (*signingKey, error) {
+	privatePEM, err := DecryptString(row.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt private key (was JWT_SECRET changed?): %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return &signingKey{
+		ID:         row.ID,
+		Algorithm:  row.Algorithm,
+		PrivateKey: private,
+		PublicKey:  private.Public(),
+	}, nil
+}
@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttemptRecord struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// AttemptStore persists failed login counters per throttling key.
+type AttemptStore interface {
+	Get(key string) (AttemptRecord, error)
+	// RecordFailure increments the counter, restarting it when the previous
+	// failure is older than window, and returns the updated record. The
+	// increment and the returned count come from one atomic step.
+	RecordFailure(key string, now time.Time, window time.Duration) (AttemptRecord, error)
+	// Forgive takes back one counted failure.
+	Forgive(key string) error
+	// Lock blocks the key until the given time and clears its counter.
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+	// Prune removes records that are neither locked nor failed since before.
+	Prune(before time.Time) error
+}
+
+// LoginThrottle applies exponential backoff after FreeAttempts failures and
+// locks the key for LockoutDuration after MaxFailures.
+type LoginThrottle struct {
+	Store           AttemptStore
+	FreeAttempts    int
+	MaxFailures     int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+// Login throttles keyed by account email and by client IP.
+var (
+	AccountLoginThrottle *LoginThrottle
+	IPLoginThrottle      *LoginThrottle
+)
+
+func InitLoginThrottles() {
+	var store AttemptStore = NewPostgresAttemptStore(config.DB)
+	if config.AppConfig.LoginLimiterStore == "memory" {
+		store = NewMemoryAttemptStore()
+	}
+
+	lockout := time.Duration(config.AppConfig.LoginLockoutMinutes) * time.Minute
+
+	AccountLoginThrottle = &LoginThrottle{
+		Store:           store,
+		FreeAttempts:    2,
+		MaxFailures:     config.AppConfig.LoginMaxFailures,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: lockout,
+		Window:          time.Hour,
+	}
+	IPLoginThrottle = &LoginThrottle{
+		Store:           store,
+		FreeAttempts:    10,
+		MaxFailures:     config.AppConfig.LoginIPMaxFailures,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: lockout,
+		Window:          time.Hour,
+	}
+}
+
+// Check returns how long the caller has to wait before the next attempt for
+// key is allowed, or zero if it may proceed.
+func (t *LoginThrottle) Check(key string, now time.Time) (time.Duration, error) {
+	record, err := t.Store.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if record.LockedUntil.After(now) {
+		return record.LockedUntil.Sub(now), nil
+	}
+
+	if record.Failures == 0 || now.Sub(record.LastFailureAt) > t.Window {
+		return 0, nil
+	}
+
+	if next := record.LastFailureAt.Add(t.backoff(record.Failures)); next.After(now) {
+		return next.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Reserve counts an attempt as failed before it is verified, so parallel
+// requests cannot slip past MaxFailures between checking and failing. It
+// returns the attempt's number and false when the attempt must be refused.
+// Every allowed attempt ends with Fail, Succeed or Release.
+func (t *LoginThrottle) Reserve(key string, now time.Time) (int, bool, error) {
+	record, err := t.Store.RecordFailure(key, now, t.Window)
+	if err != nil {
+		return 0, false, err
+	}
+	if record.LockedUntil.After(now) || record.Failures > t.MaxFailures {
+		return record.Failures, false, nil
+	}
+	return record.Failures, true, nil
+}
+
+// Fail finishes a reserved attempt that failed and reports whether it was
+// the last one allowed, locking the key.
+func (t *LoginThrottle) Fail(key string, attempt int, now time.Time) (bool, error) {
+	if attempt < t.MaxFailures {
+		return false, nil
+	}
+
+	return true, t.Store.Lock(key, now.Add(t.LockoutDuration))
+}
+
+// Release finishes a reserved attempt that succeeded without clearing the
+// earlier failures, as for an IP shared by many accounts.
+func (t *LoginThrottle) Release(key string) error {
+	return t.Store.Forgive(key)
+}
+
+func (t *LoginThrottle) Succeed(key string) error {
+	return t.Store.Reset(key)
+}
+
+func (t *LoginThrottle) backoff(failures int) time.Duration {
+	if failures <= t.FreeAttempts {
+		return 0
+	}
+
+	delay := t.BaseDelay
+	for i := t.FreeAttempts + 1; i < failures && delay < t.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.MaxDelay {
+		delay = t.MaxDelay
+	}
+	return delay
+}
+
+// MemoryAttemptStore keeps counters in process memory. It is only suitable
+// for a single instance.
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	records map[string]AttemptRecord
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{records: make(map[string]AttemptRecord)}
+}
+
+func (s *MemoryAttemptStore) Get(key string) (AttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *MemoryAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (AttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	if now.Sub(record.LastFailureAt) > window {
+		record.Failures = 0
+	}
+	record.Failures++
+	record.LastFailureAt = now
+	s.records[key] = record
+	return record, nil
+}
+
+func (s *MemoryAttemptStore) Forgive(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && record.Failures > 0 {
+		record.Failures--
+		s.records[key] = record
+	}
+	return nil
+}
+
+func (s *MemoryAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Failures = 0
+	record.LockedUntil = until
+	s.records[key] = record
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryAttemptStore) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, record := range s.records {
+		if record.LastFailureAt.Before(before) && record.LockedUntil.Before(before) {
+			delete(s.records, key)
+		}
+	}
+	return nil
+}
+
+// PostgresAttemptStore shares counters between instances through the
+// login_attempts table.
+type PostgresAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresAttemptStore(db *gorm.DB) *PostgresAttemptStore {
+	return &PostgresAttemptStore{db: db}
+}
+
+func (s *PostgresAttemptStore) Get(key string) (AttemptRecord, error) {
+	var rows []models.LoginAttempt
+	if err := s.db.Where("key = ?", key).Limit(1).Find(&rows).Error; err != nil {
+		return AttemptRecord{}, err
+	}
+	if len(rows) == 0 {
+		return AttemptRecord{}, nil
+	}
+	return attemptRecordFromModel(rows[0]), nil
+}
+
+func (s *PostgresAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (AttemptRecord, error) {
+	var row models.LoginAttempt
+	err := s.db.Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at, updated_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`,
+		key, now, now, now.Add(-window),
+	).Scan(&row).Error
+	if err != nil {
+		return AttemptRecord{}, err
+	}
+	return attemptRecordFromModel(row), nil
+}
+
+func (s *PostgresAttemptStore) Forgive(key string) error {
+	return s.db.Model(&models.LoginAttempt{}).Where("key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+func (s *PostgresAttemptStore) Lock(key string, until time.Time) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"failures", "locked_until", "updated_at"}),
+	}).Create(&models.LoginAttempt{
+		Key:           key,
+		Failures:      0,
+		LastFailureAt: time.Now(),
+		LockedUntil:   &until,
+	}).Error
+}
+
+func (s *PostgresAttemptStore) Reset(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func (s *PostgresAttemptStore) Prune(before time.Time) error {
+	return s.db.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&models.LoginAttempt{}).Error
+}
+
+func attemptRecordFromModel(row models.LoginAttempt) AttemptRecord {
+	record := AttemptRecord{
+		Failures:      row.Failures,
+		LastFailureAt: row.LastFailureAt,
+	}
+	if row.LockedUntil != nil {
+		record.LockedUntil = *row.LockedUntil
+	}
+	return record
+}
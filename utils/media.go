@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ThumbnailSize = 320 // longest side in pixels
+
+	// Larger images are refused before decoding; a small compressed file
+	// can expand to gigabytes of pixels.
+	maxImageSide   = 10000
+	maxImagePixels = 40_000_000
+)
+
+var imageContentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image")
+	ErrImageTooLarge    = errors.New("image too large")
+)
+
+func InitMedia() {
+	models.MediaURL = SignedMediaURL
+}
+
+// StoreImage validates an uploaded image, stores it and its thumbnail by
+// content hash and creates media. The caller sets the owner, purpose and
+// any purpose-specific fields beforehand. The blobs are written before tx
+// commits, so a caller whose transaction fails must pass media to
+// DiscardUnusedBlobs.
+func StoreImage(tx *gorm.DB, r io.Reader, media *models.Media) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	// Trust the bytes, not the client's declared type or file name
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(imageContentTypes, contentType) {
+		return ErrUnsupportedImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide || cfg.Width*cfg.Height > maxImagePixels {
+		return ErrImageTooLarge
+	}
+
+	sum := sha256.Sum256(data)
+	media.SHA256 = hex.EncodeToString(sum[:])
+	media.ContentType = contentType
+	media.Size = int64(len(data))
+	media.Width, media.Height = cfg.Width, cfg.Height
+	media.StorageKey = "media/" + media.SHA256[:2] + "/" + media.SHA256
+
+	// Hold the hash until tx ends so its blobs are not released meanwhile
+	if err := lockContentHash(tx, media.SHA256); err != nil {
+		return err
+	}
+
+	// Identical content was stored before; reuse its blobs
+	var existing models.Media
+	if tx.Where("sha256 = ?", media.SHA256).First(&existing).Error == nil {
+		media.StorageKey, media.ThumbnailKey, media.ThumbnailType = existing.StorageKey, existing.ThumbnailKey, existing.ThumbnailType
+		return tx.Create(media).Error
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+
+	media.ThumbnailKey, media.ThumbnailType = media.StorageKey, contentType
+	if cfg.Width > ThumbnailSize || cfg.Height > ThumbnailSize {
+		var thumb bytes.Buffer
+		resized := resizeToFit(img, ThumbnailSize)
+		if contentType == "image/jpeg" {
+			err = jpeg.Encode(&thumb, resized, &jpeg.Options{Quality: 82})
+			media.ThumbnailType = "image/jpeg"
+		} else {
+			// PNG keeps transparency; GIF thumbnails show the first frame
+			err = png.Encode(&thumb, resized)
+			media.ThumbnailType = "image/png"
+		}
+		if err != nil {
+			return err
+		}
+
+		media.ThumbnailKey = media.StorageKey + "-thumb"
+		if err := Blobs.Put(media.ThumbnailKey, &thumb); err != nil {
+			return err
+		}
+	}
+
+	if err := Blobs.Put(media.StorageKey, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	return tx.Create(media).Error
+}
+
+// ReleaseMedia deletes a media row and, once no other media shares them,
+// its blobs. Failures to delete blobs are logged, not returned.
+func ReleaseMedia(id uuid.UUID) error {
+	var media models.Media
+	if err := config.DB.First(&media, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := config.DB.Delete(&media).Error; err != nil {
+		return err
+	}
+	return DiscardUnusedBlobs(media)
+}
+
+// DiscardUnusedBlobs deletes the blobs of media unless another media row
+// shares its content. It holds the same per-hash lock as StoreImage, so an
+// upload of identical content either finishes first and keeps the blobs or
+// waits and writes them again. Failures to delete blobs are logged.
+func DiscardUnusedBlobs(media models.Media) error {
+	if media.SHA256 == "" {
+		return nil
+	}
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockContentHash(tx, media.SHA256); err != nil {
+			return err
+		}
+		var shared int64
+		if err := tx.Model(&models.Media{}).Where("sha256 = ?", media.SHA256).Count(&shared).Error; err != nil {
+			return err
+		}
+		if shared > 0 {
+			return nil
+		}
+		for _, key := range slices.Compact([]string{media.StorageKey, media.ThumbnailKey}) {
+			if err := Blobs.Delete(key); err != nil {
+				log.Printf("Failed to delete blob %s: %v", key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// lockContentHash serializes work on the blobs of one content hash until
+// tx ends.
+func lockContentHash(tx *gorm.DB, sha string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", sha).Error
+}
+
+// SignedMediaURL links to a media variant for MEDIA_URL_TTL_MINUTES. The
+// expiry is rounded up to a whole window so repeated requests produce the
+// same URL and clients can cache it.
+func SignedMediaURL(id uuid.UUID, variant models.MediaVariant) string {
+	ttl := time.Duration(config.AppConfig.MediaURLTTLMinutes) * time.Minute
+	expires := time.Now().Add(ttl).Truncate(ttl / 2).Add(ttl / 2).Unix()
+
+	query := url.Values{}
+	query.Set("variant", string(variant))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", mediaSignature(id, variant, expires))
+	return fmt.Sprintf("%s/api/v1/media/%s?%s", config.AppConfig.AppBaseURL, id, query.Encode())
+}
+
+// VerifyMediaURL checks the signature and expiry from a signed URL.
+func VerifyMediaURL(id uuid.UUID, variant models.MediaVariant, expiresParam, signature string) (time.Time, bool) {
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return time.Time{}, false
+	}
+	expected := mediaSignature(id, variant, expires)
+	return time.Unix(expires, 0), hmac.Equal([]byte(signature), []byte(expected))
+}
+
+func mediaSignature(id uuid.UUID, variant models.MediaVariant, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.MediaURLSecret))
+	fmt.Fprintf(mac, "%s\n%s\n%d", id, variant, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,333 @@
+package utils
+
+// viMessages holds the Vietnamese error messages. Keys must match the
+// English text passed to ErrorResponse exactly.
+var viMessages = map[string]string{
+	// Authentication and sessions
+	"Authorization header required":                                        "Thiếu header Authorization",
+	"Invalid authorization header format":                                  "Header Authorization không đúng định dạng",
+	"Invalid or expired token":                                             "Token không hợp lệ hoặc đã hết hạn",
+	"Session has been revoked":                                             "Phiên đăng nhập đã bị thu hồi",
+	"Invalid email or password":                                            "Email hoặc mật khẩu không đúng",
+	"User account is deactivated":                                          "Tài khoản đã bị vô hiệu hóa",
+	"A password reset is required, please use the link sent to your email": "Bạn cần đặt lại mật khẩu, vui lòng dùng liên kết đã gửi tới email của bạn",
+	"Too many login attempts, please try again later":                      "Đăng nhập sai quá nhiều lần, vui lòng thử lại sau",
+	"Too many attempts, please try again later":                            "Thử quá nhiều lần, vui lòng thử lại sau",
+	"Access denied":                                                        "Không có quyền truy cập",
+	"Failed to process login":                                              "Không thể xử lý đăng nhập",
+	"Failed to sign in":                                                    "Không thể đăng nhập",
+	"Failed to generate token":                                             "Không thể tạo token",
+	"Failed to hash password":                                              "Không thể mã hóa mật khẩu",
+	"Failed to load permissions":                                           "Không thể tải quyền truy cập",
+	"Email already registered":                                             "Email đã được đăng ký",
+	"Phone number already registered":                                      "Số điện thoại đã được đăng ký",
+	"Failed to create user":                                                "Không thể tạo người dùng",
+	"Current password is incorrect":                                        "Mật khẩu hiện tại không đúng",
+	"Password is incorrect":                                                "Mật khẩu không đúng",
+	"Failed to change password":                                            "Không thể đổi mật khẩu",
+	"Invalid or already used reset token":                                  "Token đặt lại mật khẩu không hợp lệ hoặc đã được sử dụng",
+	"Reset token has expired":                                              "Token đặt lại mật khẩu đã hết hạn",
+	"Failed to reset password":                                             "Không thể đặt lại mật khẩu",
+	"Invalid or expired challenge":                                         "Yêu cầu xác thực không hợp lệ hoặc đã hết hạn",
+
+	// Login providers
+	"Unknown login provider":                        "Nhà cung cấp đăng nhập không được hỗ trợ",
+	"Login provider is unavailable":                 "Nhà cung cấp đăng nhập hiện không khả dụng",
+	"Login was cancelled or denied by the provider": "Đăng nhập đã bị hủy hoặc bị nhà cung cấp từ chối",
+	"Missing authorization code":                    "Thiếu mã ủy quyền",
+	"Invalid or expired login state":                "Trạng thái đăng nhập không hợp lệ hoặc đã hết hạn",
+	"Could not verify login with provider":          "Không thể xác minh đăng nhập với nhà cung cấp",
+	"An account with this email already exists, log in with your password to continue": "Email này đã có tài khoản, hãy đăng nhập bằng mật khẩu để tiếp tục",
+	"Failed to start login":                                    "Không thể bắt đầu đăng nhập",
+	"Failed to fetch linked accounts":                          "Không thể tải các tài khoản liên kết",
+	"Invalid identity ID":                                      "ID tài khoản liên kết không hợp lệ",
+	"Linked account not found":                                 "Không tìm thấy tài khoản liên kết",
+	"Set a password before unlinking your last login provider": "Hãy đặt mật khẩu trước khi hủy liên kết nhà cung cấp đăng nhập cuối cùng",
+	"Failed to unlink account":                                 "Không thể hủy liên kết tài khoản",
+
+	// Verification
+	"Email is already verified":                               "Email đã được xác minh",
+	"Phone number is already verified":                        "Số điện thoại đã được xác minh",
+	"Add a phone number to your profile first":                "Vui lòng thêm số điện thoại vào hồ sơ trước",
+	"Invalid or already used verification link":               "Liên kết xác minh không hợp lệ hoặc đã được sử dụng",
+	"Verification link has expired":                           "Liên kết xác minh đã hết hạn",
+	"Invalid verification code":                               "Mã xác minh không đúng",
+	"No verification code pending, please request a new one":  "Không có mã xác minh nào đang chờ, vui lòng yêu cầu mã mới",
+	"Verification code has expired, please request a new one": "Mã xác minh đã hết hạn, vui lòng yêu cầu mã mới",
+	"Too many attempts, please request a new code":            "Thử quá nhiều lần, vui lòng yêu cầu mã mới",
+	"Too many verification requests, please try again later":  "Yêu cầu xác minh quá nhiều lần, vui lòng thử lại sau",
+	"Please verify your email address and phone number first": "Vui lòng xác minh email và số điện thoại trước",
+	"Failed to send verification":                             "Không thể gửi mã xác minh",
+	"Failed to verify code":                                   "Không thể xác minh mã",
+	"Failed to verify email":                                  "Không thể xác minh email",
+	"Failed to verify phone number":                           "Không thể xác minh số điện thoại",
+
+	// Two-factor authentication
+	"Two-factor authentication is already enabled":               "Xác thực hai lớp đã được bật",
+	"Two-factor authentication is not enabled":                   "Xác thực hai lớp chưa được bật",
+	"Two-factor authentication is mandatory for admins":          "Quản trị viên bắt buộc phải bật xác thực hai lớp",
+	"Two-factor authentication must be enabled for this account": "Tài khoản này phải bật xác thực hai lớp",
+	"Start enrollment first":                                     "Vui lòng bắt đầu đăng ký trước",
+	"Failed to start enrollment":                                 "Không thể bắt đầu đăng ký",
+	"Failed to generate secret":                                  "Không thể tạo khóa bí mật",
+	"Failed to generate recovery codes":                          "Không thể tạo mã khôi phục",
+	"Failed to enable two-factor authentication":                 "Không thể bật xác thực hai lớp",
+	"Failed to disable two-factor authentication":                "Không thể tắt xác thực hai lớp",
+
+	// Users and accounts
+	"Invalid user ID":                                                    "ID người dùng không hợp lệ",
+	"User not found":                                                     "Không tìm thấy người dùng",
+	"User role not found":                                                "Không tìm thấy vai trò người dùng",
+	"Failed to update profile":                                           "Không thể cập nhật hồ sơ",
+	"Failed to update user":                                              "Không thể cập nhật người dùng",
+	"Failed to fetch user":                                               "Không thể tải thông tin người dùng",
+	"Failed to fetch users":                                              "Không thể tải danh sách người dùng",
+	"User is already in that state":                                      "Người dùng đã ở trạng thái này",
+	"User already has that role":                                         "Người dùng đã có vai trò này",
+	"User is not deleted":                                                "Người dùng chưa bị xóa",
+	"Failed to change role":                                              "Không thể đổi vai trò",
+	"Failed to restore user":                                             "Không thể khôi phục người dùng",
+	"Only admins can grant staff or admin access":                        "Chỉ quản trị viên mới có thể cấp quyền nhân viên hoặc quản trị",
+	"Only admins can manage admin accounts":                              "Chỉ quản trị viên mới có thể quản lý tài khoản quản trị",
+	"You cannot perform this action on your own account":                 "Bạn không thể thực hiện thao tác này trên tài khoản của chính mình",
+	"Staff accounts must be removed by an administrator":                 "Tài khoản nhân viên phải do quản trị viên xóa",
+	"Account deletion is already scheduled":                              "Tài khoản đã được lên lịch xóa",
+	"Account is not scheduled for deletion":                              "Tài khoản không nằm trong lịch xóa",
+	"Account has already been anonymized and cannot be restored":         "Tài khoản đã được ẩn danh hóa và không thể khôi phục",
+	"Your account is scheduled for deletion, restore it to continue":     "Tài khoản của bạn đang chờ xóa, hãy khôi phục để tiếp tục",
+	"Finish or cancel your active bookings before deleting your account": "Hãy hoàn thành hoặc hủy các lịch đặt đang hoạt động trước khi xóa tài khoản",
+	"Failed to schedule account deletion":                                "Không thể lên lịch xóa tài khoản",
+	"Failed to restore account":                                          "Không thể khôi phục tài khoản",
+	"Failed to export data":                                              "Không thể xuất dữ liệu",
+
+	// Addresses
+	"Invalid address ID":        "ID địa chỉ không hợp lệ",
+	"Address not found":         "Không tìm thấy địa chỉ",
+	"Address book is full":      "Sổ địa chỉ đã đầy",
+	"Failed to fetch addresses": "Không thể tải danh sách địa chỉ",
+	"Failed to save address":    "Không thể lưu địa chỉ",
+	"Failed to update address":  "Không thể cập nhật địa chỉ",
+	"Failed to delete address":  "Không thể xóa địa chỉ",
+
+	// Roles and impersonation
+	"Invalid role ID":                                         "ID vai trò không hợp lệ",
+	"Role not found":                                          "Không tìm thấy vai trò",
+	"Role name already exists":                                "Tên vai trò đã tồn tại",
+	"Role is still assigned to users":                         "Vai trò vẫn đang được gán cho người dùng",
+	"System roles cannot be deleted":                          "Không thể xóa vai trò hệ thống",
+	"Unknown permission":                                      "Quyền không hợp lệ",
+	"Roles can only be assigned to staff accounts":            "Chỉ có thể gán vai trò cho tài khoản nhân viên",
+	"Invalid assignment ID":                                   "ID phân quyền không hợp lệ",
+	"Role assignment not found":                               "Không tìm thấy phân quyền",
+	"Failed to fetch roles":                                   "Không thể tải danh sách vai trò",
+	"Failed to fetch role assignments":                        "Không thể tải danh sách phân quyền",
+	"Failed to create role":                                   "Không thể tạo vai trò",
+	"Failed to update role":                                   "Không thể cập nhật vai trò",
+	"Failed to delete role":                                   "Không thể xóa vai trò",
+	"Failed to assign role":                                   "Không thể gán vai trò",
+	"Failed to revoke role":                                   "Không thể thu hồi vai trò",
+	"Only customer and worker accounts can be impersonated":   "Chỉ có thể đăng nhập thay tài khoản khách hàng và người làm",
+	"You cannot grant permissions you do not have":            "Bạn không thể cấp quyền mà bạn không có",
+	"You cannot impersonate yourself":                         "Bạn không thể đăng nhập thay chính mình",
+	"Invalid session ID":                                      "ID phiên không hợp lệ",
+	"Impersonation session not found":                         "Không tìm thấy phiên đăng nhập thay",
+	"Impersonation session has ended":                         "Phiên đăng nhập thay đã kết thúc",
+	"Impersonation session has already ended":                 "Phiên đăng nhập thay đã kết thúc trước đó",
+	"Not an impersonation session":                            "Đây không phải phiên đăng nhập thay",
+	"This action is not available while impersonating a user": "Không thể thực hiện thao tác này khi đang đăng nhập thay người dùng",
+	"Failed to start impersonation":                           "Không thể bắt đầu đăng nhập thay",
+	"Failed to end impersonation":                             "Không thể kết thúc đăng nhập thay",
+	"Failed to fetch impersonation sessions":                  "Không thể tải danh sách phiên đăng nhập thay",
+	"Failed to fetch audit log":                               "Không thể tải nhật ký hệ thống",
+	"min_rating must be between 0 and 5":                      "min_rating phải nằm trong khoảng từ 0 đến 5",
+
+	// Catalog
+	"Invalid service ID":                               "ID dịch vụ không hợp lệ",
+	"Service not found":                                "Không tìm thấy dịch vụ",
+	"Service is not deleted":                           "Dịch vụ chưa bị xóa",
+	"Service has open bookings, deactivate it instead": "Dịch vụ đang có lịch đặt, hãy tạm ngưng thay vì xóa",
+	"Restore the service's category first":             "Hãy khôi phục danh mục của dịch vụ trước",
+	"min_duration cannot be greater than max_duration": "min_duration không được lớn hơn max_duration",
+	"Failed to fetch services":                         "Không thể tải danh sách dịch vụ",
+	"Failed to create service":                         "Không thể tạo dịch vụ",
+	"Failed to update service":                         "Không thể cập nhật dịch vụ",
+	"Failed to delete service":                         "Không thể xóa dịch vụ",
+	"Failed to restore service":                        "Không thể khôi phục dịch vụ",
+	"Failed to reorder":                                "Không thể sắp xếp lại",
+	"Name cannot be empty":                             "Tên không được để trống",
+	"Invalid category ID":                              "ID danh mục không hợp lệ",
+	"Category not found":                               "Không tìm thấy danh mục",
+	"Category is not deleted":                          "Danh mục chưa bị xóa",
+	"A category with this name already exists here":    "Đã có danh mục cùng tên ở đây",
+	"A category with this name already exists there":   "Đã có danh mục cùng tên ở vị trí đó",
+	"Another category with this name now exists here":  "Hiện đã có một danh mục khác cùng tên ở đây",
+	"Category still has services or subcategories, move or delete them first": "Danh mục vẫn còn dịch vụ hoặc danh mục con, hãy chuyển hoặc xóa chúng trước",
+	"Restore the parent category first":                                       "Hãy khôi phục danh mục cha trước",
+	"Failed to fetch categories":                                              "Không thể tải danh sách danh mục",
+	"Failed to fetch category":                                                "Không thể tải danh mục",
+	"Failed to create category":                                               "Không thể tạo danh mục",
+	"Failed to update category":                                               "Không thể cập nhật danh mục",
+	"Failed to move category":                                                 "Không thể di chuyển danh mục",
+	"Failed to delete category":                                               "Không thể xóa danh mục",
+	"Failed to restore category":                                              "Không thể khôi phục danh mục",
+	"Invalid option ID":                                                       "ID tùy chọn không hợp lệ",
+	"Option not found":                                                        "Không tìm thấy tùy chọn",
+	"Failed to create option":                                                 "Không thể tạo tùy chọn",
+	"Failed to update option":                                                 "Không thể cập nhật tùy chọn",
+	"Failed to delete option":                                                 "Không thể xóa tùy chọn",
+	"Unsupported locale":                                                      "Ngôn ngữ không được hỗ trợ",
+	"Default locale content is edited on the item itself":                     "Nội dung ngôn ngữ mặc định được chỉnh sửa trực tiếp trên mục đó",
+	"Translation not found":                                                   "Không tìm thấy bản dịch",
+	"Failed to fetch translations":                                            "Không thể tải bản dịch",
+	"Failed to save translation":                                              "Không thể lưu bản dịch",
+	"Failed to delete translation":                                            "Không thể xóa bản dịch",
+	"Query parameter q is required":                                           "Thiếu tham số q",
+	"Search query is too long":                                                "Từ khóa tìm kiếm quá dài",
+	"Search query must contain letters or digits":                             "Từ khóa tìm kiếm phải chứa chữ hoặc số",
+	"type must be all, services or categories":                                "type phải là all, services hoặc categories",
+	"Search failed":                                                           "Tìm kiếm thất bại",
+	"Failed to fetch suggestions":                                             "Không thể tải gợi ý",
+
+	// Bookings, workers and reviews
+	"Invalid booking ID":                             "ID lịch đặt không hợp lệ",
+	"Booking not found":                              "Không tìm thấy lịch đặt",
+	"Booking not found or not assigned to you":       "Không tìm thấy lịch đặt hoặc lịch chưa được giao cho bạn",
+	"Booking is not pending":                         "Lịch đặt không ở trạng thái chờ",
+	"Booking is not confirmed":                       "Lịch đặt chưa được xác nhận",
+	"Booking is not in progress":                     "Lịch đặt chưa được bắt đầu",
+	"Scheduled time must be in the future":           "Thời gian hẹn phải ở tương lai",
+	"Cannot cancel this booking":                     "Không thể hủy lịch đặt này",
+	"Not authorized to cancel this booking":          "Bạn không có quyền hủy lịch đặt này",
+	"Not authorized to view this booking":            "Bạn không có quyền xem lịch đặt này",
+	"Failed to fetch bookings":                       "Không thể tải danh sách lịch đặt",
+	"Failed to create booking":                       "Không thể tạo lịch đặt",
+	"Failed to cancel booking":                       "Không thể hủy lịch đặt",
+	"Failed to accept booking":                       "Không thể nhận lịch đặt",
+	"Failed to start booking":                        "Không thể bắt đầu công việc",
+	"Failed to complete booking":                     "Không thể hoàn thành công việc",
+	"Failed to decline booking":                      "Không thể từ chối lịch đặt",
+	"Booking is reserved for another worker":         "Lịch đặt đang được giữ cho người làm khác",
+	"Preferred worker must be one of your favorites": "Người làm được chọn phải nằm trong danh sách yêu thích của bạn",
+	"Preferred worker is not available":              "Người làm được chọn hiện không sẵn sàng",
+	"Preferred worker does not offer this service":   "Người làm được chọn không cung cấp dịch vụ này",
+	"Invalid worker ID":                              "ID người làm không hợp lệ",
+	"Worker not found":                               "Không tìm thấy người làm",
+	"Worker profile not found":                       "Không tìm thấy hồ sơ người làm",
+	"Failed to fetch workers":                        "Không thể tải danh sách người làm",
+	"Failed to update worker profile":                "Không thể cập nhật hồ sơ người làm",
+	"Failed to update availability":                  "Không thể cập nhật trạng thái sẵn sàng",
+	"Failed to update services":                      "Không thể cập nhật dịch vụ cung cấp",
+	"Failed to update languages":                     "Không thể cập nhật ngôn ngữ",
+	"Failed to update schedule":                      "Không thể cập nhật lịch làm việc",
+	"You cannot add yourself":                        "Bạn không thể thêm chính mình",
+	"Worker list is full":                            "Danh sách người làm đã đầy",
+	"Failed to save worker":                          "Không thể lưu người làm",
+	"Failed to remove worker":                        "Không thể xóa người làm",
+	"Unknown or inactive service":                    "Dịch vụ không tồn tại hoặc đã ngừng hoạt động",
+	"Slot times must be in HH:MM format":             "Thời gian phải có định dạng HH:MM",
+	"Slot start must be before its end":              "Giờ bắt đầu phải trước giờ kết thúc",
+	"max_rate must be a positive number":             "max_rate phải là số dương",
+	"lat and lng must be valid coordinates":          "lat và lng phải là tọa độ hợp lệ",
+	"Sorting by distance requires lat and lng":       "Sắp xếp theo khoảng cách cần có lat và lng",
+	"Invalid available_at time, use RFC 3339":        "Thời gian available_at không hợp lệ, hãy dùng định dạng RFC 3339",
+	"duration_minutes must be between 1 and 1440":    "duration_minutes phải nằm trong khoảng từ 1 đến 1440",
+	"Booking already reviewed":                       "Lịch đặt đã được đánh giá",
+	"Can only review completed bookings":             "Chỉ có thể đánh giá lịch đặt đã hoàn thành",
+	"Failed to fetch reviews":                        "Không thể tải đánh giá",
+	"Failed to create review":                        "Không thể tạo đánh giá",
+
+	// Booking options, schedules and limits
+	"Slots on the same day must not overlap":                            "Các khung giờ trong cùng một ngày không được trùng nhau",
+	"Duration must be between %d and %d minutes in steps of %d minutes": "Thời lượng phải từ %d đến %d phút, theo bước %d phút",
+	"max_distance_km must be between 0 and %d":                          "max_distance_km phải nằm trong khoảng từ 0 đến %d",
+	"File must not exceed %d MB":                                        "Tệp không được vượt quá %d MB",
+	"Failed to load service options":                                    "Không thể tải các tùy chọn dịch vụ",
+	"Each option can only be selected once":                             "Mỗi tùy chọn chỉ được chọn một lần",
+	"Unknown option selected":                                           "Tùy chọn đã chọn không tồn tại",
+	"Option %q is required":                                             "Tùy chọn %q là bắt buộc",
+	"Option %q takes choices, not a quantity":                           "Tùy chọn %q cần chọn lựa chọn, không phải số lượng",
+	"Pick exactly one choice for %q":                                    "Hãy chọn đúng một lựa chọn cho %q",
+	"Invalid choice for %q":                                             "Lựa chọn không hợp lệ cho %q",
+	"Option %q takes a quantity, not choices":                           "Tùy chọn %q cần số lượng, không phải lựa chọn",
+	"Quantity for %q must be between %d and %d":                         "Số lượng cho %q phải từ %d đến %d",
+
+	// Review replies and moderation
+	"Invalid review ID":                           "ID đánh giá không hợp lệ",
+	"Review not found":                            "Không tìm thấy đánh giá",
+	"Reply must be between 1 and 2000 characters": "Phản hồi phải có từ 1 đến 2000 ký tự",
+	"Review already has a reply":                  "Đánh giá đã có phản hồi",
+	"Failed to save reply":                        "Không thể lưu phản hồi",
+	"Details are too long":                        "Nội dung chi tiết quá dài",
+	"You have already reported this review":       "Bạn đã báo cáo đánh giá này",
+	"Failed to report review":                     "Không thể báo cáo đánh giá",
+	"Review is already hidden":                    "Đánh giá đã bị ẩn",
+	"Review is not hidden":                        "Đánh giá không bị ẩn",
+	"Review has no open reports":                  "Đánh giá không có báo cáo nào đang mở",
+	"Failed to moderate review":                   "Không thể kiểm duyệt đánh giá",
+
+	// Worker verification
+	"Invalid document type":                                "Loại giấy tờ không hợp lệ",
+	"expires_on must be a date in YYYY-MM-DD format":       "expires_on phải là ngày theo định dạng YYYY-MM-DD",
+	"Document has already expired":                         "Giấy tờ đã hết hạn",
+	"A file is required":                                   "Cần có tệp tải lên",
+	"Too many documents awaiting submission":               "Có quá nhiều giấy tờ đang chờ gửi",
+	"Documents must be JPEG, PNG or PDF files":             "Giấy tờ phải là tệp JPEG, PNG hoặc PDF",
+	"Failed to store document":                             "Không thể lưu giấy tờ",
+	"Invalid document ID":                                  "ID giấy tờ không hợp lệ",
+	"Invalid verification ID":                              "ID hồ sơ xác minh không hợp lệ",
+	"Document not found":                                   "Không tìm thấy giấy tờ",
+	"Submitted documents cannot be deleted":                "Không thể xóa giấy tờ đã gửi",
+	"Failed to delete document":                            "Không thể xóa giấy tờ",
+	"Failed to read document":                              "Không thể đọc giấy tờ",
+	"Upload your documents before submitting":              "Hãy tải giấy tờ lên trước khi gửi",
+	"An ID card, passport or driver's license is required": "Cần có CCCD, hộ chiếu hoặc giấy phép lái xe",
+	"Verification is already under review":                 "Hồ sơ xác minh đang được xem xét",
+	"Failed to submit verification":                        "Không thể gửi hồ sơ xác minh",
+	"Failed to fetch verification":                         "Không thể tải thông tin xác minh",
+	"Failed to fetch verifications":                        "Không thể tải danh sách hồ sơ xác minh",
+	"Verification not found":                               "Không tìm thấy hồ sơ xác minh",
+	"Verification is not pending review":                   "Hồ sơ xác minh không ở trạng thái chờ duyệt",
+	"You cannot review your own verification":              "Bạn không thể tự duyệt hồ sơ xác minh của mình",
+	"A reason is required":                                 "Cần nêu lý do",
+	"Failed to update verification":                        "Không thể cập nhật hồ sơ xác minh",
+
+	// Images
+	"Images must be JPEG, PNG or GIF files":                 "Ảnh phải là tệp JPEG, PNG hoặc GIF",
+	"Image dimensions are too large":                        "Kích thước ảnh quá lớn",
+	"Failed to store image":                                 "Không thể lưu ảnh",
+	"Failed to remove image":                                "Không thể xóa ảnh",
+	"Failed to read image":                                  "Không thể đọc ảnh",
+	"Image not found":                                       "Không tìm thấy ảnh",
+	"Invalid image variant":                                 "Phiên bản ảnh không hợp lệ",
+	"Link is invalid or has expired":                        "Liên kết không hợp lệ hoặc đã hết hạn",
+	"Cannot add photos to a cancelled booking":              "Không thể thêm ảnh vào lịch đặt đã hủy",
+	"This booking already has the maximum number of photos": "Lịch đặt này đã đạt số ảnh tối đa",
+	"Failed to fetch photos":                                "Không thể tải ảnh",
+	"Photo not found":                                       "Không tìm thấy ảnh",
+	"Check-in and check-out photos cannot be deleted":       "Không thể xóa ảnh check-in và check-out",
+	"Only the uploader can delete this photo":               "Chỉ người tải lên mới có thể xóa ảnh này",
+
+	// Check-in and check-out
+	"You must be at the booking address to check in":   "Bạn phải ở địa chỉ đặt lịch để check-in",
+	"Location is required to check in":                 "Cần có vị trí để check-in",
+	"latitude and longitude must be sent together":     "latitude và longitude phải được gửi cùng nhau",
+	"latitude and longitude must be valid coordinates": "latitude và longitude phải là tọa độ hợp lệ",
+	"Location and accuracy must be numbers":            "Vị trí và độ chính xác phải là số",
+	"accuracy_meters must not be negative":             "accuracy_meters không được âm",
+	"Too many photos attached":                         "Đính kèm quá nhiều ảnh",
+	"Note is too long":                                 "Ghi chú quá dài",
+	"Invalid form data":                                "Dữ liệu biểu mẫu không hợp lệ",
+	"Failed to fetch timeline":                         "Không thể tải lịch sử lịch đặt",
+
+	// Lists and filters
+	"Invalid status filter":                        "Bộ lọc trạng thái không hợp lệ",
+	"flagged must be true or false":                "flagged phải là true hoặc false",
+	"max_reliability must be between 1 and 5":      "max_reliability phải nằm trong khoảng từ 1 đến 5",
+	"Invalid filter value":                         "Giá trị bộ lọc không hợp lệ",
+	"Invalid time, use RFC 3339":                   "Thời gian không hợp lệ, hãy dùng định dạng RFC 3339",
+	"Invalid time range, start must be before end": "Khoảng thời gian không hợp lệ, thời điểm bắt đầu phải trước thời điểm kết thúc",
+	"Limit must be a positive number":              "Limit phải là số dương",
+	"Invalid sort field":                           "Trường sắp xếp không hợp lệ",
+	"Invalid cursor":                               "Cursor không hợp lệ",
+	"Cursor does not match the requested sort":     "Cursor không khớp với cách sắp xếp được yêu cầu",
+}
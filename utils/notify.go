@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/google/uuid"
+)
+
+type EmailSender interface {
+	SendEmail(to, subject, body string) error
+}
+
+type SMSSender interface {
+	SendSMS(to, body string) error
+}
+
+// Mailer and SMS are the delivery channels used by the application. They are
+// configured by InitNotifiers and can be swapped for real providers.
+var (
+	Mailer EmailSender
+	SMS    SMSSender
+)
+
+func InitNotifiers() {
+	Mailer = newSender(config.AppConfig.MailDriver)
+	SMS = newSender(config.AppConfig.SMSDriver)
+}
+
+func newSender(driver string) interface {
+	EmailSender
+	SMSSender
+} {
+	if driver == "file" {
+		return FileSender{Dir: config.AppConfig.OutboxDir}
+	}
+	return LogSender{}
+}
+
+// LogSender writes messages to the server log. Intended for development.
+type LogSender struct{}
+
+func (LogSender) SendEmail(to, subject, body string) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+func (LogSender) SendSMS(to, body string) error {
+	log.Printf("[sms] to=%s %s", to, body)
+	return nil
+}
+
+// FileSender drops each message into Dir as a text file, which is handy for
+// inspecting outgoing mail in local environments and end-to-end tests.
+type FileSender struct {
+	Dir string
+}
+
+func (s FileSender) SendEmail(to, subject, body string) error {
+	content := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n\n%s\n", config.AppConfig.MailFrom, to, subject, body)
+	return s.write("email", content)
+}
+
+func (s FileSender) SendSMS(to, body string) error {
+	return s.write("sms", fmt.Sprintf("To: %s\n\n%s\n", to, body))
+}
+
+func (s FileSender) write(kind, content string) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s-%s.txt", time.Now().UTC().Format("20060102T150405"), kind, strings.Split(uuid.NewString(), "-")[0])
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(content), 0o600)
+}
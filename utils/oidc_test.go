@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/utils/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestProvider(issuer *oidctest.Issuer) *OIDCProvider {
+	return &OIDCProvider{OIDCProviderConfig: config.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      issuer.URL,
+		ClientID:    issuer.ClientID,
+		RedirectURL: "http://localhost/callback",
+		Scopes:      []string{"openid", "email"},
+	}}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id")
+	provider := newTestProvider(issuer)
+
+	tests := []struct {
+		name    string
+		modify  func(jwt.MapClaims)
+		nonce   string
+		unknown bool
+		wantErr bool
+	}{
+		{name: "valid", nonce: "nonce-1"},
+		{name: "nonce mismatch", nonce: "other-nonce", wantErr: true},
+		{name: "nonce not expected", nonce: "", wantErr: true},
+		{name: "wrong audience", nonce: "nonce-1", modify: func(c jwt.MapClaims) { c["aud"] = "another-client" }, wantErr: true},
+		{name: "wrong issuer", nonce: "nonce-1", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: true},
+		{name: "expired", nonce: "nonce-1", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, wantErr: true},
+		{name: "expired within leeway", nonce: "nonce-1", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() }},
+		{name: "no expiry", nonce: "nonce-1", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: true},
+		{name: "no subject", nonce: "nonce-1", modify: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: true},
+		{name: "unknown key", nonce: "nonce-1", unknown: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.Claims("subject-1", "nonce-1")
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			token := issuer.Sign(t, claims)
+			if tt.unknown {
+				token = issuer.SignWithUnknownKey(t, claims)
+			}
+
+			got, err := provider.VerifyIDToken(context.Background(), token, tt.nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyIDToken() accepted the token, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if got.Subject != "subject-1" {
+				t.Errorf("Subject = %q, want %q", got.Subject, "subject-1")
+			}
+		})
+	}
+}
+
+func TestExchange(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id")
+	provider := newTestProvider(issuer)
+
+	claims := issuer.Claims("subject-1", "nonce-1")
+	claims["email"] = "user@example.com"
+	claims["email_verified"] = "true"
+	issuer.Issue("code-1", issuer.Sign(t, claims))
+
+	got, err := provider.Exchange(context.Background(), "code-1", "verifier", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if got.Email != "user@example.com" || !got.EmailVerified {
+		t.Errorf("claims = %+v, want the verified email", got)
+	}
+
+	// Codes are single use
+	if _, err := provider.Exchange(context.Background(), "code-1", "verifier", "nonce-1"); err == nil {
+		t.Errorf("Exchange() accepted a used code")
+	}
+}
@@ -0,0 +1,150 @@
+// Package oidctest runs a fake OpenID Connect issuer for tests. It serves
+// discovery, a JWKS and a token endpoint, and signs ID tokens with a key
+// generated per issuer.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "test-key"
+
+type Issuer struct {
+	URL      string
+	ClientID string
+
+	key    *rsa.PrivateKey
+	mu     sync.Mutex
+	tokens map[string]string // authorization code to ID token
+}
+
+// NewIssuer starts an issuer that is shut down when the test ends.
+func NewIssuer(t *testing.T, clientID string) *Issuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	issuer := &Issuer{ClientID: clientID, key: key, tokens: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", issuer.serveDiscovery)
+	mux.HandleFunc("GET /jwks", issuer.serveJWKS)
+	mux.HandleFunc("POST /token", issuer.serveToken)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	issuer.URL = server.URL
+	return issuer
+}
+
+// Claims returns valid claims for subject and nonce, to be adjusted by the
+// test before signing.
+func (i *Issuer) Claims(subject, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"sub":   subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+}
+
+// Sign returns an ID token signed with the issuer's published key.
+func (i *Issuer) Sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	return sign(t, i.key, claims)
+}
+
+// SignWithUnknownKey returns an ID token signed with a key the JWKS does
+// not contain.
+func (i *Issuer) SignWithUnknownKey(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return sign(t, key, claims)
+}
+
+// Issue makes the token endpoint answer code with idToken.
+func (i *Issuer) Issue(code, idToken string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.tokens[code] = idToken
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func (i *Issuer) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *Issuer) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != i.ClientID ||
+		r.PostForm.Get("code_verifier") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	idToken, ok := i.tokens[r.PostForm.Get("code")]
+	delete(i.tokens, r.PostForm.Get("code"))
+	i.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"token_type": "Bearer", "access_token": "access", "id_token": idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type SortType int
+
+const (
+	SortTime SortType = iota
+	SortNumber
+	SortString
+)
+
+// SortField is a column a list can be sorted by. The column must be NOT
+// NULL, otherwise keyset comparisons silently skip rows.
+type SortField struct {
+	Column string
+	Type   SortType
+}
+
+// PageMeta is returned alongside a page of results. Pass next_cursor back as
+// ?cursor= to fetch the following page.
+type PageMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// PageQuery is a parsed ?limit=&sort=&cursor= request. Results are ordered
+// by the sort column and then by ID, so pages are stable even when many rows
+// share a sort value.
+type PageQuery struct {
+	Limit    int
+	SortKey  string
+	Desc     bool
+	field    SortField
+	idColumn string
+	after    *pageCursor
+}
+
+type pageCursor struct {
+	Sort  string      `json:"s"`
+	Desc  bool        `json:"d,omitempty"`
+	Value interface{} `json:"v"`
+	ID    uuid.UUID   `json:"id"`
+}
+
+// ParsePageQuery reads limit, sort and cursor. sort is a key of sorts,
+// prefixed with "-" for descending order; defaultSort uses the same form.
+// idColumn is the tie-breaker, qualified when the query joins other tables.
+// Returned errors are *ParamError.
+func ParsePageQuery(c *gin.Context, sorts map[string]SortField, defaultSort, idColumn string) (*PageQuery, error) {
+	p := &PageQuery{Limit: DefaultPageLimit, idColumn: idColumn}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return nil, &ParamError{Message: "Limit must be a positive number", Param: "limit"}
+		}
+		p.Limit = min(limit, MaxPageLimit)
+	}
+
+	sort := c.DefaultQuery("sort", defaultSort)
+	p.SortKey, p.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	field, ok := sorts[p.SortKey]
+	if !ok {
+		return nil, &ParamError{Message: "Invalid sort field", Param: "sort"}
+	}
+	p.field = field
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw, field.Type)
+		if err != nil {
+			return nil, &ParamError{Message: "Invalid cursor", Param: "cursor"}
+		}
+		if cursor.Sort != p.SortKey || cursor.Desc != p.Desc {
+			return nil, &ParamError{Message: "Cursor does not match the requested sort", Param: "cursor"}
+		}
+		p.after = cursor
+	}
+
+	return p, nil
+}
+
+// Apply adds the cursor condition, ordering and limit to query. One extra
+// row is fetched so Page can tell whether more results follow.
+func (p *PageQuery) Apply(query *gorm.DB) *gorm.DB {
+	direction, comparison := "ASC", ">"
+	if p.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if p.after != nil {
+		query = query.Where("("+p.field.Column+", "+p.idColumn+") "+comparison+" (?, ?)", p.after.Value, p.after.ID)
+	}
+	return query.
+		Order(p.field.Column + " " + direction).
+		Order(p.idColumn + " " + direction).
+		Limit(p.Limit + 1)
+}
+
+// Page trims the extra row fetched by Apply and builds the page metadata.
+// key returns the sort value and ID of an item, matching the sort field.
+func Page[T any](p *PageQuery, items []T, key func(T) (interface{}, uuid.UUID)) ([]T, PageMeta) {
+	if len(items) <= p.Limit {
+		return items, PageMeta{}
+	}
+
+	items = items[:p.Limit]
+	value, id := key(items[len(items)-1])
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC().Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(pageCursor{Sort: p.SortKey, Desc: p.Desc, Value: value, ID: id})
+	return items, PageMeta{NextCursor: base64.RawURLEncoding.EncodeToString(data), HasMore: true}
+}
+
+func decodeCursor(raw string, sortType SortType) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	switch sortType {
+	case SortTime:
+		s, ok := cursor.Value.(string)
+		if !ok {
+			return nil, errors.New("cursor value is not a time")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		cursor.Value = t
+	case SortNumber:
+		n, ok := cursor.Value.(float64)
+		if !ok {
+			return nil, errors.New("cursor value is not a number")
+		}
+		// JSON numbers decode as float64; integer columns need an integer
+		if n == math.Trunc(n) {
+			cursor.Value = int64(n)
+		}
+	case SortString:
+		if _, ok := cursor.Value.(string); !ok {
+			return nil, errors.New("cursor value is not a string")
+		}
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PermissionSet is the effective set of permissions of a user, each with the
+// scopes it was granted in.
+type PermissionSet struct {
+	superuser bool
+	grants    map[models.Permission][]models.Scope
+}
+
+func LoadPermissions(userID uuid.UUID, role models.UserRole) (*PermissionSet, error) {
+	set := &PermissionSet{grants: map[models.Permission][]models.Scope{}}
+
+	// Admins keep full access; everyone else gets what their roles grant
+	if role == models.RoleAdmin {
+		set.superuser = true
+		return set, nil
+	}
+
+	var rows []struct {
+		Permission models.Permission
+		Scope      models.Scope
+	}
+	if err := config.DB.Table("user_role_assignments").
+		Select("role_permissions.permission, user_role_assignments.scope").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_role_assignments.role_id").
+		Where("user_role_assignments.user_id = ?", userID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		set.grants[row.Permission] = append(set.grants[row.Permission], row.Scope)
+	}
+	return set, nil
+}
+
+// CurrentPermissions loads the authenticated user's permissions once per
+// request and caches them on the context.
+func CurrentPermissions(c *gin.Context) (*PermissionSet, error) {
+	if cached, ok := c.Get("permissions"); ok {
+		return cached.(*PermissionSet), nil
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("userRole")
+	id, _ := userID.(uuid.UUID)
+	roleName, _ := role.(string)
+
+	set, err := LoadPermissions(id, models.UserRole(roleName))
+	if err != nil {
+		return nil, err
+	}
+	c.Set("permissions", set)
+	return set, nil
+}
+
+// Has reports whether the permission was granted in any scope.
+func (p *PermissionSet) Has(perm models.Permission) bool {
+	return p.superuser || len(p.grants[perm]) > 0
+}
+
+// Allows reports whether the permission covers a resource with the given
+// attributes, e.g. {"region": "hanoi"}.
+func (p *PermissionSet) Allows(perm models.Permission, attributes map[string]string) bool {
+	if p.superuser {
+		return true
+	}
+	for _, scope := range p.grants[perm] {
+		if scope.Matches(attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyScope narrows query to the resources the permission covers. columns
+// maps scope attributes to SQL columns; scopes on unmapped attributes match
+// nothing. The second result is false when nothing is covered at all.
+func (p *PermissionSet) ApplyScope(query *gorm.DB, perm models.Permission, columns map[string]string) (*gorm.DB, bool) {
+	if p.superuser {
+		return query, true
+	}
+
+	scopes := p.grants[perm]
+	if len(scopes) == 0 {
+		return query, false
+	}
+
+	var clauses []string
+	var args []interface{}
+	for _, scope := range scopes {
+		if len(scope) == 0 {
+			return query, true
+		}
+
+		var parts []string
+		var scopeArgs []interface{}
+		valid := true
+		for attribute, value := range scope {
+			column, ok := columns[attribute]
+			if !ok {
+				valid = false
+				break
+			}
+			parts = append(parts, column+" = ?")
+			scopeArgs = append(scopeArgs, value)
+		}
+		if valid {
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+			args = append(args, scopeArgs...)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return query, false
+	}
+	return query.Where(strings.Join(clauses, " OR "), args...), true
+}
+
+// NormalizeScopeValue folds case, accents and spacing out of a scope value
+// or resource attribute, with the same database function the stored values
+// were migrated with.
+func NormalizeScopeValue(value string) (string, error) {
+	var normalized string
+	err := config.DB.Raw("SELECT goodstuff_scope_value(?)", value).Scan(&normalized).Error
+	return normalized, err
+}
+
+// SeedSystemRoles creates the built-in roles that do not exist yet. Existing
+// roles are left alone so edits made by admins survive restarts.
+func SeedSystemRoles() error {
+	for _, role := range models.SystemRoles {
+		var count int64
+		if err := config.DB.Model(&models.Role{}).Where("name = ?", role.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		role.IsSystem = true
+		role.Permissions = append([]models.RolePermission(nil), role.Permissions...)
+		if err := config.DB.Create(&role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
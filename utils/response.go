@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,6 +13,8 @@ type APIResponse struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Detail  string      `json:"detail,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
 }
 
 func SuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
@@ -19,9 +25,57 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
+// PagedResponse is SuccessResponse for list endpoints, adding the cursor
+// metadata for the next page.
+func PagedResponse(c *gin.Context, statusCode int, message string, data interface{}, meta PageMeta) {
+	c.JSON(statusCode, APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    &meta,
+	})
+}
+
+// ErrorResponse sends message translated into the request's locale.
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
 	c.JSON(statusCode, APIResponse{
 		Success: false,
-		Error:   message,
+		Error:   Translate(Locale(c), message),
+	})
+}
+
+// ErrorResponsef is ErrorResponse for a message with arguments. format is
+// the catalog key; it is translated before the arguments are filled in.
+func ErrorResponsef(c *gin.Context, statusCode int, format string, args ...interface{}) {
+	c.JSON(statusCode, APIResponse{
+		Success: false,
+		Error:   fmt.Sprintf(Translate(Locale(c), format), args...),
+	})
+}
+
+// Message is a client-facing error built by a helper and sent later with
+// ErrorResponsef.
+type Message struct {
+	Format string
+	Args   []interface{}
+}
+
+func Messagef(format string, args ...interface{}) *Message {
+	return &Message{Format: format, Args: args}
+}
+
+// ParamErrorResponse rejects a request with a bad query parameter. For a
+// *ParamError the message is translated and the parameter is named in
+// detail.
+func ParamErrorResponse(c *gin.Context, err error) {
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) {
+		ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Success: false,
+		Error:   Translate(Locale(c), paramErr.Message),
+		Detail:  paramErr.Param,
 	})
 }
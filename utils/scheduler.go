@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"log"
+	"time"
+)
+
+// RunPeriodically calls fn every interval in a background goroutine,
+// logging rather than propagating failures.
+func RunPeriodically(name string, interval time.Duration, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := fn(); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"log"
+	"time"
+
+	"github.com/DucLUT/goodstuff/config"
+	"github.com/DucLUT/goodstuff/models"
+	"gorm.io/gorm"
+)
+
+// ExpireWorkerVerifications lapses approved verifications past their expiry
+// date. The worker stays unverified until a renewal is approved; a renewal
+// already under review keeps its status.
+func ExpireWorkerVerifications() error {
+	now := time.Now()
+
+	// Expired rows drop out of the query, so each batch picks up the next
+	for {
+		var verifications []models.WorkerVerification
+		if err := config.DB.Where("status = ? AND expires_at <= ?", models.VerificationApproved, now).
+			Limit(100).Find(&verifications).Error; err != nil {
+			return err
+		}
+		if len(verifications) == 0 {
+			return nil
+		}
+
+		for _, verification := range verifications {
+			if err := expireWorkerVerification(verification, now); err != nil {
+				return err
+			}
+			log.Printf("Worker verification %s expired", verification.ID)
+		}
+	}
+}
+
+func expireWorkerVerification(verification models.WorkerVerification, now time.Time) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&verification).Update("status", models.VerificationExpired).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.WorkerVerificationEvent{
+			VerificationID: verification.ID,
+			Status:         models.VerificationExpired,
+		}).Error; err != nil {
+			return err
+		}
+
+		// A renewal approved in the meantime has moved verified_until on
+		return tx.Model(&models.Worker{}).
+			Where("id = ? AND (verified_until IS NULL OR verified_until <= ?)", verification.WorkerID, now).
+			Updates(map[string]interface{}{
+				"is_verified":    false,
+				"verified_until": nil,
+				"verification_status": gorm.Expr("CASE WHEN verification_status = ? THEN ? ELSE verification_status END",
+					models.VerificationApproved, models.VerificationExpired),
+			}).Error
+	})
+}